@@ -1,8 +1,13 @@
 package lapi
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
+	"log"
 	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/goline/errors"
 )
@@ -11,9 +16,12 @@ import (
 type App interface {
 	AppLoader
 	AppRunner
+	AppServer
+	AppCloser
 	AppRouter
 	AppRescuer
 	AppConfigger
+	AppLimiter
 	ContainerAware
 	http.Handler
 }
@@ -32,6 +40,15 @@ type AppConfigger interface {
 	WithConfig(config Bag) App
 }
 
+// AppLimiter restricts requests of application
+type AppLimiter interface {
+	// BodyLimit returns maximum size of request's body in bytes, it is unlimited if 0
+	BodyLimit() int64
+
+	// WithBodyLimit sets maximum size of request's body in bytes, routes could override it
+	WithBodyLimit(size int64) App
+}
+
 // AppRouter handles router
 type AppRouter interface {
 	// Router returns an instance of Router
@@ -50,11 +67,32 @@ type AppRescuer interface {
 	WithRescuer(handler Rescuer) App
 }
 
+// AppServer manages application's http server
+type AppServer interface {
+	// Server returns http server, it is nil if server is not set up yet
+	Server() *http.Server
+
+	// WithServer sets http server
+	WithServer(server *http.Server) App
+}
+
+// AppCloser stops application
+type AppCloser interface {
+	// Shutdown stops server gracefully, it waits for in-flight requests until ctx is done.
+	// Then closers of loaders are run in reverse priority order
+	Shutdown(ctx context.Context) error
+}
+
 // AppRunner runs application
 type AppRunner interface {
 	// Run brings application up
 	// Any errors should manage inside this method
 	Run()
+
+	// Check verifies that injectable dependencies of route handlers and hooks could be resolved.
+	// It returns an error reporting all problems at once. Run checks once other loaders are loaded,
+	// right before blocking loaders, such as ServerLoader, start
+	Check() errors.Error
 }
 
 func NewApp() App {
@@ -73,6 +111,8 @@ type FactoryApp struct {
 	loaders   map[int]*Slice
 	router    Router
 	rescuer   Rescuer
+	server    *http.Server
+	bodyLimit int64
 }
 
 func (a *FactoryApp) WithLoader(loader Loader) App {
@@ -120,6 +160,44 @@ func (a *FactoryApp) WithRescuer(handler Rescuer) App {
 	return a
 }
 
+func (a *FactoryApp) BodyLimit() int64 {
+	return a.bodyLimit
+}
+
+func (a *FactoryApp) WithBodyLimit(size int64) App {
+	a.bodyLimit = size
+	return a
+}
+
+func (a *FactoryApp) Server() *http.Server {
+	return a.server
+}
+
+func (a *FactoryApp) WithServer(server *http.Server) App {
+	a.server = server
+	return a
+}
+
+func (a *FactoryApp) Shutdown(ctx context.Context) error {
+	var err error
+	if a.server != nil {
+		err = a.server.Shutdown(ctx)
+	}
+
+	loaders := Sorted(a.loaders)
+	for i := len(loaders) - 1; i >= 0; i-- {
+		closer, ok := loaders[i].(ClosableLoader)
+		if ok == false {
+			continue
+		}
+
+		if e := closer.Close(a); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
 func (a *FactoryApp) Container() Container {
 	return a.container
 }
@@ -142,18 +220,74 @@ func (a *FactoryApp) Run() {
 		panic(errors.New(ERR_ROUTER_NOT_DEFINED, fmt.Sprint("Router is not defined yet.")))
 	}
 
+	// blocking loaders run until shutdown, so they start after others are loaded and checked
 	Parallel(a.loaders, func(l interface{}) {
+		if isBlockingLoader(l) == true {
+			return
+		}
 		l.(Loader).Load(a)
 	})
+	PanicOnError(a.Check())
+
+	var wg sync.WaitGroup
+	for _, l := range Sorted(a.loaders) {
+		if isBlockingLoader(l) == false {
+			continue
+		}
+		wg.Add(1)
+		go func(l Loader) {
+			defer wg.Done()
+			l.Load(a)
+		}(l.(Loader))
+	}
+	wg.Wait()
+}
+
+func isBlockingLoader(l interface{}) bool {
+	b, ok := l.(BlockingLoader)
+	return ok == true && b.Blocking() == true
+}
+
+func (a *FactoryApp) Check() errors.Error {
+	problems := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, route := range a.router.Routes() {
+		targets := Sorted(route.Hooks())
+		if route.Handler() != nil {
+			targets = append(targets, route.Handler())
+		}
+
+		for _, target := range targets {
+			for _, problem := range a.container.Check(target) {
+				if seen[problem] == false {
+					seen[problem] = true
+					problems = append(problems, problem)
+				}
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New(ERR_DEPENDENCY_CHECK_FAILURE, fmt.Sprintf("Unable to resolve %d dependencies:\n  - %s", len(problems), strings.Join(problems, "\n  - ")))
 }
 
 func (a *FactoryApp) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	connection := a.setUpConnection(w, r)
+	defer a.disposeScope(connection)
 	defer a.forceSendResponse(connection)
 	defer a.forceRecover(connection)
 
-	PanicOnError(a.router.Route(connection.Request()))
-	Parallel(connection.Request().Route().Hooks(), func(item interface{}) {
+	if err := a.router.Route(connection.Request()); err != nil {
+		if err.Code() == ERR_HTTP_METHOD_NOT_ALLOWED {
+			methods := a.router.Methods(connection.Request())
+			connection.Response().Header().Set(HEADER_ALLOW, strings.Join(methods, ", "))
+		}
+		panic(err)
+	}
+	a.limitBody(connection)
+	runHooks(connection.Request().Route(), func(item interface{}) {
 		if hook, ok := item.(BootableHook); ok == true {
 			defer a.forceRecover(connection)
 			PanicOnError(hook.SetUp(connection))
@@ -168,12 +302,14 @@ func (a *FactoryApp) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		panic(errors.New(ERR_NO_HANDLER_FOUND, "No handler found"))
 	}
 
+	// handler is shared between requests, so it is injected from application's container.
+	// Scoped concretes should be resolved via connection.Container()
 	PanicOnError(a.container.Inject(handler))
 	if h, ok := handler.(ContainerAware); ok == true {
 		h.WithContainer(a.container)
 	}
-	result, err := handler.Handle(connection)
-	Parallel(connection.Request().Route().Hooks(), func(item interface{}) {
+	result, err := chain(connection.Request().Route().Hooks(), handler, connection)()
+	runHooks(connection.Request().Route(), func(item interface{}) {
 		if hook, ok := item.(HaltableHook); ok == true {
 			defer a.forceRecover(connection)
 			PanicOnError(hook.TearDown(connection, result, err))
@@ -181,12 +317,55 @@ func (a *FactoryApp) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// limitBody applies body limit of matched route, or application's one
+func (a *FactoryApp) limitBody(connection Connection) {
+	limit := connection.Request().Route().BodyLimit()
+	if limit <= 0 {
+		limit = a.bodyLimit
+	}
+	if limit <= 0 {
+		return
+	}
+
+	if r := connection.Request().Ancestor(); r != nil && r.ContentLength > limit {
+		panic(errors.New(ERR_HTTP_REQUEST_ENTITY_TOO_LARGE, fmt.Sprintf("Content exceeds %d bytes", limit)).
+			WithStatus(http.StatusRequestEntityTooLarge))
+	}
+	connection.Request().Body().WithMaxSize(limit)
+}
+
 func (a *FactoryApp) forceSendResponse(connection Connection) {
 	if connection.Response().IsSent() == false {
 		connection.Response().Send()
 	}
 }
 
+// disposeScope runs after response is sent and errors are rescued, so its errors are only logged
+func (a *FactoryApp) disposeScope(connection Connection) {
+	if response, ok := connection.Response().(Disposable); ok == true {
+		a.logError(response.Dispose())
+	}
+	if request, ok := connection.Request().(Disposable); ok == true {
+		a.logError(request.Dispose())
+	}
+	if connection.Container() != nil {
+		a.logError(connection.Container().Dispose())
+	}
+}
+
+// logError writes err to server's ErrorLog, or standard logger
+func (a *FactoryApp) logError(err error) {
+	if err == nil {
+		return
+	}
+
+	if a.server != nil && a.server.ErrorLog != nil {
+		a.server.ErrorLog.Printf("lapi: %s", err.Error())
+		return
+	}
+	log.Printf("lapi: %s", err.Error())
+}
+
 func (a *FactoryApp) forceRecover(connection Connection) {
 	if r := recover(); r != nil {
 		PanicOnError(a.rescuer.Rescue(connection, r))
@@ -201,6 +380,14 @@ func (a *FactoryApp) forceRecover(connection Connection) {
 func (a *FactoryApp) setUpConnection(w http.ResponseWriter, r *http.Request) Connection {
 	request := NewRequest(r)
 	response := NewJsonResponse(w)
+	if request.Method() == http.MethodHead {
+		// response to HEAD request must not contain a body
+		response.WithBody(NewBody(nil, ioutil.Discard)).Body().
+			WithContentType(CONTENT_TYPE_JSON).
+			WithCharset(CONTENT_CHARSET_DEFAULT)
+	}
 
-	return NewConnection(request, response)
+	connection := NewConnection(request, response)
+	connection.WithContainer(a.container.Scope())
+	return connection
 }
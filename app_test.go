@@ -1,12 +1,18 @@
 package lapi
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"github.com/goline/errors"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"time"
 )
 
 var _ = Describe("App", func() {
@@ -34,4 +40,320 @@ var _ = Describe("FactoryApp", func() {
 		Expect(err).To(BeNil())
 		Expect(resErr.Code).To(Equal(ERR_HTTP_NOT_FOUND))
 	})
+
+	It("should return response with error code ERR_HTTP_METHOD_NOT_ALLOWED", func() {
+		app := NewApp()
+		app.Router().Get("/foo", nil)
+		app.Router().WithHook(new(SystemHook)).WithHook(new(ParserHook))
+		app.Run()
+
+		req := httptest.NewRequest("POST", "/foo", nil)
+		rw := httptest.NewRecorder()
+		app.ServeHTTP(rw, req)
+		res := rw.Result()
+		Expect(res.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+		Expect(res.Header.Get("Allow")).To(Equal("GET"))
+	})
+
+	It("should drop response body of HEAD request", func() {
+		app := NewApp()
+		app.Router().WithAutoInform(true).Get("/foo", &appHandler{})
+		app.Router().WithHook(new(SystemHook)).WithHook(new(ParserHook))
+		app.Run()
+
+		req := httptest.NewRequest("HEAD", "/foo", nil)
+		rw := httptest.NewRecorder()
+		app.ServeHTTP(rw, req)
+		res := rw.Result()
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		body, _ := ioutil.ReadAll(res.Body)
+		Expect(len(body)).To(BeZero())
+	})
+
+	It("should answer OPTIONS request from registered routes", func() {
+		app := NewApp()
+		app.Router().WithAutoInform(true).Get("/foo", &appHandler{})
+		app.Router().Delete("/foo", &appHandler{})
+		app.Run()
+
+		req := httptest.NewRequest("OPTIONS", "/foo", nil)
+		rw := httptest.NewRecorder()
+		app.ServeHTTP(rw, req)
+		res := rw.Result()
+		Expect(res.StatusCode).To(Equal(http.StatusNoContent))
+		Expect(res.Header.Get("Allow")).To(Equal("DELETE, GET, HEAD, OPTIONS"))
+	})
+})
+
+var _ = Describe("FactoryApp Check", func() {
+	It("Run should panic with all unresolvable dependencies", func() {
+		app := NewApp()
+		app.Router().Get("/foo", &appInjectedHandler{})
+		app.Router().Get("/bar", &appInjectedHandler{}).WithHook(&appInjectedHandler{})
+
+		err := app.Check()
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_DEPENDENCY_CHECK_FAILURE))
+		Expect(err.Message()).To(Equal("Unable to resolve 2 dependencies:\n" +
+			"  - *lapi.appInjectedHandler.Foo: lapi.InjectFooer is not bound yet\n" +
+			"  - *lapi.appInjectedHandler.Replica: lapi.InjectBazer#replica is not bound yet"))
+		Expect(func() { app.Run() }).To(Panic())
+
+		app.Container().Bind((*InjectFooer)(nil), &InjectFoo{})
+		app.Container().Bind((*InjectBazer)(nil), &InjectBaz{})
+		app.Container().BindNamed("replica", (*InjectBazer)(nil), &InjectBaz{})
+		Expect(app.Check()).To(BeNil())
+	})
+
+	It("Run should check routes of all loaders before starting server", func() {
+		app := NewApp()
+		app.Config().Set("server.address", "127.0.0.1:0")
+		app.WithServer(new(http.Server))
+		app.WithLoader(new(ServerLoader))
+		app.WithLoader(NewLoader(func(app App) {
+			app.Router().Get("/foo", &appInjectedHandler{})
+		}, PRIORITY_DEFAULT+1))
+
+		Expect(func() { app.Run() }).To(Panic())
+		Expect(app.Server().Handler).To(BeNil())
+	})
+
+	It("Run should start blocking loaders concurrently and wait for all of them", func() {
+		started := make(chan int, 2)
+		release := make(chan struct{})
+		app := NewApp()
+		app.WithLoader(&appBlockingLoader{PriorityAware{1}, started, release})
+		app.WithLoader(&appBlockingLoader{PriorityAware{2}, started, release})
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			app.Run()
+		}()
+		Expect([]int{<-started, <-started}).To(ConsistOf(1, 2))
+		select {
+		case <-done:
+			Fail("Run should wait for blocking loaders")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		close(release)
+		<-done
+	})
+})
+
+type appBlockingLoader struct {
+	PriorityAware
+	started chan int
+	release chan struct{}
+}
+
+func (l *appBlockingLoader) Blocking() bool { return true }
+func (l *appBlockingLoader) Load(app App) {
+	l.started <- l.Priority()
+	<-l.release
+}
+
+type appInjectedHandler struct {
+	Foo     InjectFooer `inject:"*"`
+	Replica InjectBazer `inject:"replica"`
+}
+
+func (h *appInjectedHandler) Handle(c Connection) (interface{}, errors.Error) { return nil, nil }
+
+var _ = Describe("FactoryApp MiddlewareHook", func() {
+	It("should compose middleware hooks around handler in priority order", func() {
+		calls := make([]string, 0)
+		app := NewApp()
+		app.Router().Get("/foo", &appHandler{}).
+			WithHook(&appMiddleware{"inner", 10, &calls}).
+			WithHook(&appMiddleware{"outer", 1, &calls}).
+			WithHook(new(SystemHook)).
+			WithHook(new(ParserHook))
+		app.Run()
+
+		req := httptest.NewRequest("GET", "/foo", nil)
+		rw := httptest.NewRecorder()
+		app.ServeHTTP(rw, req)
+		res := rw.Result()
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		Expect(calls).To(Equal([]string{"outer", "inner", "inner", "outer"}))
+
+		body, _ := ioutil.ReadAll(res.Body)
+		Expect(string(body)).To(Equal(`{"foo":"bar","outer":"yes"}`))
+	})
+})
+
+type appMiddleware struct {
+	name     string
+	priority int
+	calls    *[]string
+}
+
+func (h *appMiddleware) Priority() int { return h.priority }
+func (h *appMiddleware) Handle(c Connection, next func() (interface{}, errors.Error)) (interface{}, errors.Error) {
+	*h.calls = append(*h.calls, h.name)
+	result, err := next()
+	*h.calls = append(*h.calls, h.name)
+	if h.name == "outer" {
+		result.(map[string]string)["outer"] = "yes"
+	}
+	return result, err
+}
+
+var _ = Describe("FactoryApp sequential hooks", func() {
+	It("should run hooks in registration order when route's hooks are sequential", func() {
+		calls := make([]string, 0)
+		app := NewApp()
+		app.Router().Get("/foo", &appHandler{}).
+			WithHook(&appSetUpHook{"auth", false, &calls}).
+			WithHook(&appSetUpHook{"rate", false, &calls}).
+			WithHook(&appSetUpHook{"validate", false, &calls})
+		app.Router().WithSequentialHooks(true)
+		app.Run()
+
+		for i := 0; i < 10; i++ {
+			calls = calls[:0]
+			app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/foo", nil))
+			Expect(calls).To(Equal([]string{"auth", "rate", "validate"}))
+		}
+	})
+
+	It("should run SequentialHook alone in registration order", func() {
+		calls := make([]string, 0)
+		app := NewApp()
+		app.Router().Get("/foo", &appHandler{}).
+			WithHook(&appSetUpHook{"auth", true, &calls}).
+			WithHook(&appSetUpHook{"rate", true, &calls}).
+			WithHook(&appSetUpHook{"validate", true, &calls})
+		app.Run()
+
+		for i := 0; i < 10; i++ {
+			calls = calls[:0]
+			app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/foo", nil))
+			Expect(calls).To(Equal([]string{"auth", "rate", "validate"}))
+		}
+	})
 })
+
+type appSetUpHook struct {
+	name       string
+	sequential bool
+	calls      *[]string
+}
+
+func (h *appSetUpHook) Sequential() bool { return h.sequential }
+func (h *appSetUpHook) SetUp(c Connection) errors.Error {
+	*h.calls = append(*h.calls, h.name)
+	return nil
+}
+
+var _ = Describe("FactoryApp scope", func() {
+	It("should give a request-scoped container and dispose it after response is sent", func() {
+		disposed := make([]string, 0)
+		app := NewApp()
+		app.Container().BindScoped((*InjectFooer)(nil), func() InjectFooer { return &disposableFoo{"foo", &disposed} })
+		handler := &appScopedHandler{}
+		app.Router().Get("/foo", handler)
+		app.Run()
+
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/foo", nil))
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/foo", nil))
+		Expect(len(handler.fooers)).To(Equal(2))
+		Expect(handler.fooers[0] == handler.fooers[1]).To(BeFalse())
+		Expect(disposed).To(Equal([]string{"foo", "foo"}))
+	})
+
+	It("should log errors of disposing scope", func() {
+		logs := new(bytes.Buffer)
+		app := NewApp()
+		app.WithServer(&http.Server{ErrorLog: log.New(logs, "", 0)})
+		app.Container().BindScoped((*InjectFooer)(nil), func() InjectFooer { return new(appFailingDisposer) })
+		app.Router().Get("/foo", &appScopedHandler{})
+		app.Run()
+
+		w := httptest.NewRecorder()
+		Expect(func() { app.ServeHTTP(w, httptest.NewRequest("GET", "/foo", nil)) }).NotTo(Panic())
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(logs.String()).To(ContainSubstring("Unable to dispose scoped concrete"))
+	})
+})
+
+type appScopedHandler struct {
+	fooers []InjectFooer
+}
+
+func (h *appScopedHandler) Handle(c Connection) (interface{}, errors.Error) {
+	f, err := c.Container().Resolve((*InjectFooer)(nil))
+	if err != nil {
+		return nil, err
+	}
+	h.fooers = append(h.fooers, f.(InjectFooer))
+	return nil, nil
+}
+
+var _ = Describe("FactoryApp body limit", func() {
+	It("should return response with error code ERR_HTTP_REQUEST_ENTITY_TOO_LARGE", func() {
+		app := NewApp().WithBodyLimit(8)
+		app.Router().Post("/foo", &appBodyHandler{}).WithHook(new(SystemHook)).WithHook(new(ParserHook))
+		app.Router().Post("/bar", &appBodyHandler{}).WithBodyLimit(64).WithHook(new(SystemHook)).WithHook(new(ParserHook))
+		app.Run()
+
+		for uri, status := range map[string]int{"/foo": http.StatusRequestEntityTooLarge, "/bar": http.StatusOK} {
+			req := httptest.NewRequest("POST", uri, strings.NewReader(`{"foo":"0123456789"}`))
+			req.ContentLength = -1
+			rw := httptest.NewRecorder()
+			app.ServeHTTP(rw, req)
+			Expect(rw.Result().StatusCode).To(Equal(status))
+		}
+
+		req := httptest.NewRequest("POST", "/foo", strings.NewReader(`{"foo":"0123456789"}`))
+		rw := httptest.NewRecorder()
+		app.ServeHTTP(rw, req)
+		Expect(rw.Result().StatusCode).To(Equal(http.StatusRequestEntityTooLarge))
+		body, _ := ioutil.ReadAll(rw.Result().Body)
+		Expect(string(body)).To(ContainSubstring(ERR_HTTP_REQUEST_ENTITY_TOO_LARGE))
+	})
+})
+
+type appBodyHandler struct{}
+
+func (h *appBodyHandler) Handle(c Connection) (interface{}, errors.Error) {
+	input := make(map[string]string)
+	if err := c.Request().Body().Read(&input); err != nil {
+		return nil, err
+	}
+	return input, nil
+}
+
+var _ = Describe("FactoryApp Shutdown", func() {
+	It("should close loaders in reverse priority order", func() {
+		closed := make([]int, 0)
+		closer := func(i int) func(App) errors.Error {
+			return func(App) errors.Error {
+				closed = append(closed, i)
+				return nil
+			}
+		}
+		app := NewApp()
+		app.WithLoader(NewLoader(func(App) {}, 1).WithCloser(closer(1))).
+			WithLoader(NewLoader(func(App) {}, 5).WithCloser(closer(5))).
+			WithLoader(NewLoader(func(App) {}, 5).WithCloser(closer(6))).
+			WithLoader(NewLoader(func(App) {}, 3))
+		Expect(app.Shutdown(context.Background())).To(BeNil())
+		Expect(closed).To(Equal([]int{6, 5, 1}))
+	})
+})
+
+type appFailingDisposer struct{}
+
+func (f *appFailingDisposer) Foo() string { return "foo" }
+func (f *appFailingDisposer) Dispose() error {
+	return errors.New(ERR_DISPOSE_FAILURE, "disk is gone")
+}
+
+type appHandler struct{}
+
+func (h *appHandler) Handle(c Connection) (interface{}, errors.Error) {
+	return map[string]string{"foo": "bar"}, nil
+}
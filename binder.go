@@ -0,0 +1,307 @@
+package lapi
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goline/errors"
+)
+
+// ParamBinder fills a struct from request's parameters
+type ParamBinder interface {
+	// Bind sets target's fields which are tagged with one of following tags:
+	//
+	//	path:"id"           path parameter, such as placeholder <id:\d+>
+	//	query:"page"        query parameter, slices receive all values of parameter
+	//	header:"X-Tenant"   header, slices receive comma-separated values
+	//	cookie:"session"    cookie's value
+	//
+	// A `default:"1"` tag is used when value is missing, and `layout:"2006-01-02"`
+	// parses time.Time which is RFC3339 by default. Bind returns ERR_HTTP_BAD_REQUEST
+	// listing every field which could not be converted, see FieldErrors.
+	// Bound fields are validated then
+	Bind(request Request, target interface{}) errors.Error
+
+	// Validator returns validator which checks bound fields, it could be nil
+	Validator() Validator
+
+	// WithValidator sets validator
+	WithValidator(validator Validator) ParamBinder
+}
+
+// NewParamBinder returns an instance of ParamBinder, bound fields are validated by NewValidator
+func NewParamBinder() ParamBinder {
+	return &FactoryParamBinder{validator: NewValidator()}
+}
+
+type FactoryParamBinder struct {
+	validator Validator
+}
+
+// bindingSources are tags which could be bound, in order of lookup
+var bindingSources = []string{"path", "query", "header", "cookie"}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func (b *FactoryParamBinder) Bind(request Request, target interface{}) errors.Error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return errors.New(ERR_BIND_INVALID_TARGET, fmt.Sprintf("Binding to %T is not supported. Expects a pointer to struct", target))
+	}
+
+	fields := b.bindStruct(request, v.Elem())
+	if len(fields) > 0 {
+		return NewFieldErrors(fields)
+	}
+	return validatePart(b.validator, target, isParameterField)
+}
+
+func (b *FactoryParamBinder) Validator() Validator {
+	return b.validator
+}
+
+func (b *FactoryParamBinder) WithValidator(validator Validator) ParamBinder {
+	b.validator = validator
+	return b
+}
+
+func (b *FactoryParamBinder) bindStruct(request Request, v reflect.Value) []FieldError {
+	fields := make([]FieldError, 0)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous == true && sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, b.bindStruct(request, v.Field(i))...)
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		for _, source := range bindingSources {
+			key, ok := sf.Tag.Lookup(source)
+			if ok == false {
+				continue
+			}
+
+			if source == "path" && b.assign(request, key, v.Field(i)) == true {
+				break
+			}
+
+			values, ok := b.lookup(request, source, key, sf.Type)
+			if ok == false {
+				d, ok := sf.Tag.Lookup("default")
+				if ok == false {
+					break
+				}
+				values = b.split(d, sf.Type)
+			}
+
+			if err := b.set(v.Field(i), values, sf.Tag.Get("layout")); err != nil {
+				fields = append(fields, FieldError{Field: key, In: source, Message: err.Error()})
+			}
+			break
+		}
+	}
+	return fields
+}
+
+// assign sets path parameter which is already converted by typed placeholder, such as <id:int>
+func (b *FactoryParamBinder) assign(request Request, key string, field reflect.Value) bool {
+	p, ok := request.Param(key)
+	if ok == false {
+		return false
+	}
+
+	if _, ok := p.(string); ok == true {
+		return false
+	}
+
+	v := reflect.ValueOf(p)
+	switch {
+	case v.Type().AssignableTo(field.Type()):
+		field.Set(v)
+	case v.Type().ConvertibleTo(field.Type()) && v.Kind() != reflect.String && field.Kind() != reflect.String && b.fits(v, field):
+		field.Set(v.Convert(field.Type()))
+	default:
+		return false
+	}
+	return true
+}
+
+// fits reports whether number v is converted into field without overflow nor truncation,
+// otherwise value is left to convert() which reports the problem
+func (b *FactoryParamBinder) fits(v reflect.Value, field reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch field.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return field.OverflowInt(v.Int()) == false
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return v.Int() >= 0 && field.OverflowUint(uint64(v.Int())) == false
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		switch field.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return v.Uint() <= math.MaxInt64 && field.OverflowInt(int64(v.Uint())) == false
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return field.OverflowUint(v.Uint()) == false
+		}
+	case reflect.Float32, reflect.Float64:
+		switch field.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return field.OverflowFloat(v.Float()) == false
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return false
+		}
+	}
+	return true
+}
+
+// lookup returns values of key from source, headers are split by comma only for slice fields of type t
+func (b *FactoryParamBinder) lookup(request Request, source string, key string, t reflect.Type) ([]string, bool) {
+	switch source {
+	case "path":
+		if v, ok := request.Param(key); ok == true {
+			return b.stringsOf(v), true
+		}
+	case "query":
+		if a := request.Ancestor(); a != nil && a.URL != nil {
+			values, ok := a.URL.Query()[key]
+			return values, ok
+		}
+		if v, ok := request.Param(key); ok == true {
+			return b.stringsOf(v), true
+		}
+	case "header":
+		if v, ok := request.Header().Get(key); ok == true {
+			return b.split(v, t), true
+		}
+	case "cookie":
+		if c, ok := request.Cookie(key); ok == true {
+			return []string{c.Value}, true
+		}
+	}
+	return nil, false
+}
+
+func (b *FactoryParamBinder) stringsOf(v interface{}) []string {
+	switch s := v.(type) {
+	case []string:
+		return s
+	case string:
+		return []string{s}
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// split breaks default value or header of a slice field by comma
+func (b *FactoryParamBinder) split(s string, t reflect.Type) []string {
+	if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+		return strings.Split(s, ",")
+	}
+	return []string{s}
+}
+
+func (b *FactoryParamBinder) set(v reflect.Value, values []string, layout string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		items := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := b.set(items.Index(i), []string{strings.TrimSpace(value)}, layout); err != nil {
+				return err
+			}
+		}
+		v.Set(items)
+		return nil
+	}
+
+	if v.Kind() == reflect.Ptr {
+		p := reflect.New(v.Type().Elem())
+		if err := b.set(p.Elem(), values, layout); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	}
+
+	return b.convert(v, values[0], layout)
+}
+
+// convert parses s into v
+func (b *FactoryParamBinder) convert(v reflect.Value, s string, layout string) error {
+	switch v.Type() {
+	case timeType:
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return fmt.Errorf("must be a time of layout %s", layout)
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("must be a duration")
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("is invalid: %s", err.Error())
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("must be a boolean")
+		}
+		v.SetBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		v.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be an unsigned integer")
+		}
+		v.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		v.SetFloat(value)
+	case reflect.Slice:
+		v.SetBytes([]byte(s))
+	default:
+		return fmt.Errorf("has non-supported type %v", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package lapi
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type binderInput struct {
+	binderPaging
+	Id       int64         `path:"id"`
+	Tags     []string      `query:"tag"`
+	Active   *bool         `query:"active"`
+	Since    time.Time     `query:"since" layout:"2006-01-02"`
+	Timeout  time.Duration `query:"timeout" default:"5s"`
+	Tenant   string        `header:"X-Tenant"`
+	Accepts  []string      `header:"Accept-Language"`
+	Session  string        `cookie:"session"`
+	Untagged string
+}
+
+type binderPaging struct {
+	Page  int     `query:"page" default:"1"`
+	Ratio float64 `query:"ratio"`
+}
+
+var _ = Describe("FactoryParamBinder", func() {
+	It("Bind should fill struct from request's parameters", func() {
+		req, _ := http.NewRequest("GET", "/users/15?tag=a&tag=b&active=true&since=2020-01-02&ratio=0.5", nil)
+		req.Header.Set("X-Tenant", "acme, inc")
+		req.Header.Set("Accept-Language", "en, vi")
+		req.AddCookie(&http.Cookie{Name: "session", Value: "s1"})
+		r := NewRequest(req)
+		r.WithParam("id", "15")
+
+		input := new(binderInput)
+		Expect(r.Bind(input)).To(BeNil())
+		Expect(input.Id).To(Equal(int64(15)))
+		Expect(input.Tags).To(Equal([]string{"a", "b"}))
+		Expect(*input.Active).To(BeTrue())
+		Expect(input.Since).To(Equal(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)))
+		Expect(input.Timeout).To(Equal(5 * time.Second))
+		Expect(input.Tenant).To(Equal("acme, inc"))
+		Expect(input.Accepts).To(Equal([]string{"en", "vi"}))
+		Expect(input.Session).To(Equal("s1"))
+		Expect(input.Page).To(Equal(1))
+		Expect(input.Ratio).To(Equal(0.5))
+	})
+
+	It("Bind should return error code ERR_HTTP_BAD_REQUEST listing every bad field", func() {
+		req, _ := http.NewRequest("GET", "/users?page=x&active=maybe&since=2020", nil)
+		r := NewRequest(req)
+		r.WithParam("id", "abc")
+
+		err := r.Bind(new(binderInput))
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_HTTP_BAD_REQUEST))
+		Expect(err.Message()).To(Equal(`Invalid parameters: query "page" must be an integer; ` +
+			`path "id" must be an integer; query "active" must be a boolean; ` +
+			`query "since" must be a time of layout 2006-01-02`))
+	})
+
+	It("Bind should assign path parameters of typed placeholders", func() {
+		r := NewRequest(nil)
+		r.WithParam("id", int64(15))
+		input := new(binderInput)
+		Expect(r.Bind(input)).To(BeNil())
+		Expect(input.Id).To(Equal(int64(15)))
+	})
+
+	It("Bind should reject path parameters overflowing typed fields", func() {
+		type sized struct {
+			Small int8   `path:"small"`
+			Count uint16 `path:"count"`
+		}
+		r := NewRequest(nil)
+		r.WithParam("small", int64(300))
+		r.WithParam("count", int64(-1))
+		err := r.Bind(new(sized))
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_HTTP_BAD_REQUEST))
+		Expect(err.(FieldErrors).Fields()).To(HaveLen(2))
+
+		r.WithParam("small", int64(-5))
+		r.WithParam("count", int64(300))
+		input := new(sized)
+		Expect(r.Bind(input)).To(BeNil())
+		Expect(input.Small).To(Equal(int8(-5)))
+		Expect(input.Count).To(Equal(uint16(300)))
+	})
+
+	It("Bind should return error code ERR_BIND_INVALID_TARGET", func() {
+		err := NewParamBinder().Bind(NewRequest(nil), binderInput{})
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_BIND_INVALID_TARGET))
+	})
+})
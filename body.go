@@ -1,10 +1,14 @@
 package lapi
 
 import (
+	"compress/gzip"
+	"compress/zlib"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"reflect"
+	"strings"
 
 	"github.com/goline/errors"
 )
@@ -13,9 +17,30 @@ type Body interface {
 	BodyRW
 	BodyContent
 	BodyFlusher
+	BodyLimiter
+	BodyValidator
 	ParserManager
 }
 
+// BodyLimiter restricts size of content which is read
+type BodyLimiter interface {
+	// MaxSize returns maximum number of bytes to read, it is unlimited if 0
+	MaxSize() int64
+
+	// WithMaxSize sets maximum number of bytes to read.
+	// Read returns ERR_HTTP_REQUEST_ENTITY_TOO_LARGE when content is bigger
+	WithMaxSize(size int64) Body
+}
+
+// BodyValidator validates input after it is read
+type BodyValidator interface {
+	// Validator returns validator, it could be nil
+	Validator() Validator
+
+	// WithValidator sets validator
+	WithValidator(validator Validator) Body
+}
+
 // BodyContent handles body's content
 type BodyContent interface {
 	// ContentType returns type of body's content
@@ -29,6 +54,12 @@ type BodyContent interface {
 
 	// WithCharset sets charset of response
 	WithCharset(charset string) Body
+
+	// ContentEncoding returns encoding of body's content, such as gzip
+	ContentEncoding() string
+
+	// WithContentEncoding sets encoding of body's content, Read decompresses gzip and deflate content
+	WithContentEncoding(encoding string) Body
 }
 
 type BodyRW interface {
@@ -44,6 +75,9 @@ type BodyReader interface {
 type BodyWriter interface {
 	// Writes puts output into writer
 	Write(output interface{}) errors.Error
+
+	// Bytes returns content which is going to be flushed
+	Bytes() []byte
 }
 
 type BodyFlusher interface {
@@ -57,6 +91,7 @@ func NewBody(reader io.Reader, writer io.Writer) Body {
 		writer: writer,
 
 		ParserManager: NewParserManager(),
+		validator:     NewValidator(),
 	}
 }
 
@@ -67,6 +102,9 @@ type FactoryBody struct {
 	contentBytes []byte
 	contentType  string
 	charset      string
+	encoding     string
+	validator    Validator
+	maxSize      int64
 }
 
 func (b *FactoryBody) Read(input interface{}) errors.Error {
@@ -79,22 +117,111 @@ func (b *FactoryBody) Read(input interface{}) errors.Error {
 		}
 	}()
 
-	bytes, err := ioutil.ReadAll(b.reader)
+	reader, err := b.decompress(b.reader)
 	if err != nil {
-		return errors.New(ERR_BODY_READER_FAILURE, "Unable to read resource.").WithDebug(err.Error())
+		return err
+	}
+
+	// limit is applied on decompressed content
+	var tooLarge errors.Error
+	if b.maxSize > 0 {
+		tooLarge = errors.New(ERR_HTTP_REQUEST_ENTITY_TOO_LARGE, fmt.Sprintf("Content exceeds %d bytes", b.maxSize)).
+			WithStatus(http.StatusRequestEntityTooLarge)
+		reader = &limitedReader{reader: reader, remaining: b.maxSize, err: tooLarge}
+	}
+
+	if p, ok := b.streamParser(); ok == true {
+		if err := p.DecodeStream(reader, input); err != nil {
+			if tooLarge != nil && isEntityTooLarge(err) == true {
+				return tooLarge
+			}
+			return errors.New(ERR_PARSE_DECODE_FAILURE, "Unable to decode content").WithDebug(err.Error())
+		}
+	} else {
+		bytes, err := ioutil.ReadAll(reader)
+		if tooLarge != nil && isEntityTooLarge(err) == true {
+			return tooLarge
+		} else if err != nil {
+			return errors.New(ERR_BODY_READER_FAILURE, "Unable to read resource.").WithDebug(err.Error())
+		}
+
+		p, ok := b.Parser(b.contentType)
+		if ok == false {
+			return errors.New(ERR_NO_PARSER_FOUND, fmt.Sprintf("Unable to find an appropriate parser for %s", b.contentType))
+		}
+
+		if err = p.Decode(bytes, input); err != nil {
+			return errors.New(ERR_PARSE_DECODE_FAILURE, "Unable to decode content").WithDebug(err.Error())
+		}
+	}
+
+	// fields bound from request's parameters are validated by ParamBinder
+	return validatePart(b.validator, input, func(field reflect.StructField) bool {
+		return isParameterField(field) == false
+	})
+}
+
+// streamParser returns parser of content-type if it could decode from reader directly
+func (b *FactoryBody) streamParser() (StreamParser, bool) {
+	if b.ParserManager == nil {
+		return nil, false
 	}
 
 	p, ok := b.Parser(b.contentType)
 	if ok == false {
-		return errors.New(ERR_NO_PARSER_FOUND, fmt.Sprintf("Unable to find an appropriate parser for %s", b.contentType))
+		return nil, false
+	}
+
+	sp, ok := p.(StreamParser)
+	return sp, ok
+}
+
+// isEntityTooLarge tells whether err is caused by exceeding body's limit, parsers might wrap it
+func isEntityTooLarge(err error) bool {
+	e, ok := causeOf(err)
+	return ok == true && e.Code() == ERR_HTTP_REQUEST_ENTITY_TOO_LARGE
+}
+
+// decompress returns reader of decoded content
+func (b *FactoryBody) decompress(reader io.Reader) (io.Reader, errors.Error) {
+	var r io.Reader
+	var err error
+	switch b.encoding {
+	case "", ENCODING_IDENTITY:
+		return reader, nil
+	case ENCODING_GZIP, "x-gzip":
+		r, err = gzip.NewReader(reader)
+	case ENCODING_DEFLATE:
+		r, err = zlib.NewReader(reader)
+	default:
+		return nil, errors.New(ERR_BODY_UNSUPPORTED_ENCODING, fmt.Sprintf("Content encoding %s is not supported", b.encoding)).
+			WithStatus(http.StatusUnsupportedMediaType)
 	}
 
-	err = p.Decode(bytes, input)
 	if err != nil {
-		return errors.New(ERR_PARSE_DECODE_FAILURE, "Unable to decode content").WithDebug(err.Error())
+		return nil, errors.New(ERR_BODY_READER_FAILURE, fmt.Sprintf("Unable to decompress %s content", b.encoding)).
+			WithStatus(http.StatusBadRequest).
+			WithDebug(err.Error())
 	}
+	return r, nil
+}
 
-	return nil
+func (b *FactoryBody) MaxSize() int64 {
+	return b.maxSize
+}
+
+func (b *FactoryBody) WithMaxSize(size int64) Body {
+	b.maxSize = size
+	return b
+}
+
+func (b *FactoryBody) Validator() Validator {
+	return b.validator
+}
+
+func (b *FactoryBody) WithValidator(validator Validator) Body {
+	b.validator = validator
+	return b
 }
 
 func (b *FactoryBody) Write(output interface{}) errors.Error {
@@ -128,6 +255,10 @@ func (b *FactoryBody) Write(output interface{}) errors.Error {
 	return nil
 }
 
+func (b *FactoryBody) Bytes() []byte {
+	return b.contentBytes
+}
+
 func (b *FactoryBody) Flush() errors.Error {
 	if b.writer == nil {
 		return errors.New(ERR_BODY_WRITER_MISSING, "Writer must not be nil")
@@ -150,6 +281,15 @@ func (b *FactoryBody) WithContentType(contentType string) Body {
 	return b
 }
 
+func (b *FactoryBody) ContentEncoding() string {
+	return b.encoding
+}
+
+func (b *FactoryBody) WithContentEncoding(encoding string) Body {
+	b.encoding = strings.ToLower(strings.TrimSpace(encoding))
+	return b
+}
+
 func (b *FactoryBody) Charset() string {
 	return b.charset
 }
@@ -158,3 +298,26 @@ func (b *FactoryBody) WithCharset(charset string) Body {
 	b.charset = charset
 	return b
 }
+
+// limitedReader returns err when reader has more than remaining bytes
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+	err       errors.Error
+}
+
+func (r *limitedReader) Read(b []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, r.err
+	}
+	if int64(len(b)) > r.remaining+1 {
+		b = b[:r.remaining+1]
+	}
+
+	n, err := r.reader.Read(b)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n + int(r.remaining), r.err
+	}
+	return n, err
+}
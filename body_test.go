@@ -5,6 +5,11 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
 	"strings"
 )
 
@@ -30,6 +35,17 @@ func (p *sampleParserForBody) ContentType() string {
 	return CONTENT_TYPE_JSON
 }
 
+type sampleWrappingParser struct {
+	JsonParser
+}
+
+func (p *sampleWrappingParser) DecodeStream(reader io.Reader, v interface{}) error {
+	if err := p.JsonParser.DecodeStream(reader, v); err != nil {
+		return fmt.Errorf("unable to decode: %w", err)
+	}
+	return nil
+}
+
 type sampleIOReader struct{}
 
 func (r *sampleIOReader) Read(p []byte) (n int, err error) {
@@ -120,6 +136,89 @@ var _ = Describe("FactoryBody", func() {
 		Expect(i.Price).To(Equal(float64(10.2)))
 	})
 
+	It("Read should return error code ERR_PARSE_DECODE_FAILURE for trailing data", func() {
+		for _, content := range []string{`{"price": 10.2} {}`, `{"price": 10.2}}`, `{"price": 10.2} x`} {
+			b := NewBody(strings.NewReader(content), nil)
+			b.WithContentType(CONTENT_TYPE_JSON).WithParser(new(JsonParser))
+			err := b.Read(new(sampleBodyItem))
+			Expect(err).NotTo(BeNil())
+			Expect(err.Code()).To(Equal(ERR_PARSE_DECODE_FAILURE))
+		}
+
+		b := NewBody(strings.NewReader("{\"price\": 10.2}\n"), nil)
+		b.WithContentType(CONTENT_TYPE_JSON).WithParser(new(JsonParser))
+		Expect(b.Read(new(sampleBodyItem))).To(BeNil())
+
+		for _, content := range []string{`<a><Price>10.2</Price></a><a/>`, `<a><Price>10.2</Price></a>x`} {
+			b := NewBody(strings.NewReader(content), nil)
+			b.WithContentType(CONTENT_TYPE_XML).WithParser(new(XmlParser))
+			err := b.Read(new(sampleBodyItem))
+			Expect(err).NotTo(BeNil())
+			Expect(err.Code()).To(Equal(ERR_PARSE_DECODE_FAILURE))
+		}
+
+		i := new(sampleBodyItem)
+		b = NewBody(strings.NewReader("<a><Price>10.2</Price></a>\n<!-- end -->\n"), nil)
+		b.WithContentType(CONTENT_TYPE_XML).WithParser(new(XmlParser))
+		Expect(b.Read(i)).To(BeNil())
+		Expect(i.Price).To(Equal(float64(10.2)))
+	})
+
+	It("Read should return error code ERR_HTTP_REQUEST_ENTITY_TOO_LARGE", func() {
+		for _, parser := range []Parser{new(JsonParser), new(TextParser)} {
+			b := NewBody(strings.NewReader(`"0123456789"`), nil)
+			b.WithParser(parser).WithDefaultParser(parser)
+			var s string
+			err := b.WithMaxSize(8).Read(&s)
+			Expect(err).NotTo(BeNil())
+			Expect(err.Code()).To(Equal(ERR_HTTP_REQUEST_ENTITY_TOO_LARGE))
+			Expect(err.Status()).To(Equal(http.StatusRequestEntityTooLarge))
+		}
+
+		var s string
+		b := NewBody(strings.NewReader(`"0123456789"`), nil)
+		b.WithParser(new(sampleWrappingParser)).WithDefaultParser(new(sampleWrappingParser))
+		err := b.WithMaxSize(8).Read(&s)
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_HTTP_REQUEST_ENTITY_TOO_LARGE))
+		Expect(err.Status()).To(Equal(http.StatusRequestEntityTooLarge))
+
+		b = NewBody(strings.NewReader(`"0123456789"`), nil)
+		b.WithParser(new(JsonParser)).WithDefaultParser(new(JsonParser))
+		Expect(b.WithMaxSize(12).Read(&s)).To(BeNil())
+		Expect(s).To(Equal("0123456789"))
+	})
+
+	It("Read should decompress gzip content", func() {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write([]byte(`{"price": 10.2}`))
+		w.Close()
+
+		b := NewBody(&buf, nil).WithContentEncoding("GZIP")
+		b.WithParser(new(JsonParser)).WithDefaultParser(new(JsonParser))
+		i := new(sampleBodyItem)
+		Expect(b.Read(i)).To(BeNil())
+		Expect(i.Price).To(Equal(float64(10.2)))
+
+		err := NewBody(strings.NewReader("plain"), nil).WithContentEncoding("gzip").Read(i)
+		Expect(err.Code()).To(Equal(ERR_BODY_READER_FAILURE))
+		Expect(err.Status()).To(Equal(http.StatusBadRequest))
+
+		err = NewBody(strings.NewReader("plain"), nil).WithContentEncoding("br").Read(i)
+		Expect(err.Code()).To(Equal(ERR_BODY_UNSUPPORTED_ENCODING))
+	})
+
+	It("Read should validate input", func() {
+		b := NewBody(strings.NewReader(`{"name": ""}`), nil)
+		b.WithParser(new(JsonParser))
+		b.WithContentType(CONTENT_TYPE_JSON)
+		err := b.Read(new(validatorInput))
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_HTTP_BAD_REQUEST))
+		Expect(err.(FieldErrors).Fields()[0]).To(Equal(FieldError{"name", "", "is required"}))
+	})
+
 	It("Write should return nil when writing nil", func() {
 		b := &FactoryBody{ParserManager: NewParserManager()}
 		err := b.Write(nil)
@@ -0,0 +1,121 @@
+package lapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/goline/errors"
+)
+
+// CheckPreconditions evaluates If-Match, If-Unmodified-Since, If-None-Match and If-Modified-Since
+// headers against current validators of an existing resource, etag could be empty and lastModified
+// could be zero if they are unknown, "*" matches the resource anyway.
+// Handlers of unsafe methods should call it before changing resource.
+// It returns ERR_HTTP_PRECONDITION_FAILED on failure. It returns true if client's copy is fresh,
+// then response's status is set to 304 Not Modified and body is cleared
+func CheckPreconditions(c Connection, etag string, lastModified time.Time) (bool, errors.Error) {
+	return checkPreconditions(c, true, etag, lastModified)
+}
+
+// CheckMissingPreconditions evaluates preconditions for a resource which does not exist yet,
+// such as PUT with If-None-Match: * which creates resource only once. Any If-Match fails
+func CheckMissingPreconditions(c Connection) errors.Error {
+	_, err := checkPreconditions(c, false, "", time.Time{})
+	return err
+}
+
+func checkPreconditions(c Connection, exists bool, etag string, lastModified time.Time) (bool, errors.Error) {
+	status := evaluatePreconditions(c.Request(), exists, etag, lastModified)
+	switch status {
+	case http.StatusPreconditionFailed:
+		return false, errors.New(ERR_HTTP_PRECONDITION_FAILED, "Precondition failed").WithStatus(http.StatusPreconditionFailed)
+	case http.StatusNotModified:
+		c.Response().WithStatus(http.StatusNotModified)
+		if err := c.Response().Body().Write([]byte{}); err != nil {
+			return false, err
+		}
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// evaluatePreconditions returns 304 or 412 as of RFC 7232 section 6, otherwise it returns 0
+func evaluatePreconditions(request Request, exists bool, etag string, lastModified time.Time) int {
+	header := request.Header()
+	safe := request.Method() == http.MethodGet || request.Method() == http.MethodHead
+
+	if ifMatch, ok := header.Get(HEADER_IF_MATCH); ok == true {
+		if exists == false || matchETag(ifMatch, etag, false) == false {
+			return http.StatusPreconditionFailed
+		}
+	} else if since, ok := parseHTTPTime(header, HEADER_IF_UNMODIFIED_SINCE); ok == true && lastModified.IsZero() == false {
+		if lastModified.Truncate(time.Second).After(since) {
+			return http.StatusPreconditionFailed
+		}
+	}
+
+	if ifNoneMatch, ok := header.Get(HEADER_IF_NONE_MATCH); ok == true {
+		if exists == false || matchETag(ifNoneMatch, etag, true) == false {
+			return 0
+		}
+		if safe == true {
+			return http.StatusNotModified
+		}
+		return http.StatusPreconditionFailed
+	}
+
+	if since, ok := parseHTTPTime(header, HEADER_IF_MODIFIED_SINCE); ok == true && safe == true && lastModified.IsZero() == false {
+		if lastModified.Truncate(time.Second).After(since) == false {
+			return http.StatusNotModified
+		}
+	}
+	return 0
+}
+
+// matchETag checks etag of an existing resource against a list of entity tags, such as "a", W/"b" or *.
+// "*" matches even if etag is unknown. Weak comparison ignores W/ prefix, strong comparison never matches weak tags
+func matchETag(list string, etag string, weak bool) bool {
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "*" {
+			return true
+		}
+		if etag == "" {
+			continue
+		}
+
+		if weak == true {
+			if strings.TrimPrefix(item, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		} else if strings.HasPrefix(item, "W/") == false && strings.HasPrefix(etag, "W/") == false && item == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func parseHTTPTime(header Header, key string) (time.Time, bool) {
+	value, ok := header.Get(key)
+	if ok == false {
+		return time.Time{}, false
+	}
+
+	t, err := http.ParseTime(value)
+	return t, err == nil
+}
+
+// computeETag returns an entity tag of content
+func computeETag(content []byte, weak bool) string {
+	sum := sha256.Sum256(content)
+	etag := fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:16]))
+	if weak == true {
+		return "W/" + etag
+	}
+	return etag
+}
@@ -0,0 +1,141 @@
+package lapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/goline/errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var conditionalModified = time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)
+
+type conditionalHandler struct{}
+
+func (h *conditionalHandler) Handle(c Connection) (interface{}, errors.Error) {
+	c.Response().Header().Set("Last-Modified", conditionalModified.Format(http.TimeFormat))
+	return map[string]string{"foo": "bar"}, nil
+}
+
+type conditionalUpdateHandler struct {
+	updated bool
+}
+
+func (h *conditionalUpdateHandler) Handle(c Connection) (interface{}, errors.Error) {
+	if _, err := CheckPreconditions(c, `"v1"`, time.Time{}); err != nil {
+		return nil, err
+	}
+	h.updated = true
+	return nil, nil
+}
+
+func serveConditional(method string, header map[string]string, hook *ETagHook) *httptest.ResponseRecorder {
+	app := NewApp()
+	app.Router().Get("/foo", &conditionalHandler{}).
+		WithHook(new(SystemHook)).WithHook(new(ParserHook)).WithHook(hook)
+	app.Router().Put("/foo", &conditionalUpdateHandler{}).
+		WithHook(new(SystemHook)).WithHook(new(ParserHook)).WithHook(hook)
+	app.Run()
+
+	req := httptest.NewRequest(method, "/foo", nil)
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	rw := httptest.NewRecorder()
+	app.ServeHTTP(rw, req)
+	return rw
+}
+
+var _ = Describe("ETagHook", func() {
+	It("should set ETag computed from body", func() {
+		rw := serveConditional("GET", nil, new(ETagHook))
+		Expect(rw.Code).To(Equal(http.StatusOK))
+		Expect(rw.Header().Get("ETag")).To(Equal(computeETag([]byte(`{"foo":"bar"}`), false)))
+		Expect(rw.Body.String()).To(Equal(`{"foo":"bar"}`))
+
+		rw = serveConditional("GET", nil, &ETagHook{Weak: true})
+		Expect(rw.Header().Get("ETag")).To(HavePrefix(`W/"`))
+	})
+
+	It("should answer 304 without body", func() {
+		etag := computeETag([]byte(`{"foo":"bar"}`), false)
+		headers := []map[string]string{
+			{"If-None-Match": `"x", W/` + etag},
+			{"If-Modified-Since": conditionalModified.Format(http.TimeFormat)},
+		}
+		for _, header := range headers {
+			rw := serveConditional("GET", header, new(ETagHook))
+			Expect(rw.Code).To(Equal(http.StatusNotModified))
+			Expect(rw.Body.Len()).To(BeZero())
+			Expect(rw.Header().Get("ETag")).To(Equal(etag))
+		}
+
+		rw := serveConditional("GET", map[string]string{"If-Modified-Since": conditionalModified.Add(-time.Hour).Format(http.TimeFormat)}, new(ETagHook))
+		Expect(rw.Code).To(Equal(http.StatusOK))
+	})
+
+	It("should answer 412 when precondition fails", func() {
+		headers := []map[string]string{
+			{"If-Match": `"x"`},
+			{"If-Unmodified-Since": conditionalModified.Add(-time.Hour).Format(http.TimeFormat)},
+		}
+		for _, header := range headers {
+			rw := serveConditional("GET", header, new(ETagHook))
+			Expect(rw.Code).To(Equal(http.StatusPreconditionFailed))
+			Expect(rw.Body.String()).To(ContainSubstring(ERR_HTTP_PRECONDITION_FAILED))
+		}
+	})
+})
+
+var _ = Describe("CheckPreconditions", func() {
+	It("should let handler reject stale updates", func() {
+		Expect(serveConditional("PUT", map[string]string{"If-Match": `"v0"`}, new(ETagHook)).Code).To(Equal(http.StatusPreconditionFailed))
+		Expect(serveConditional("PUT", map[string]string{"If-Match": `"v1"`}, new(ETagHook)).Code).To(Equal(http.StatusOK))
+		Expect(serveConditional("PUT", map[string]string{"If-None-Match": `*`}, new(ETagHook)).Code).To(Equal(http.StatusPreconditionFailed))
+	})
+
+	It("should match * against existing resource without validators", func() {
+		check := func(method string, key string) (bool, errors.Error) {
+			req := httptest.NewRequest(method, "/foo", nil)
+			req.Header.Set(key, "*")
+			return CheckPreconditions(NewConnection(NewRequest(req), NewJsonResponse(httptest.NewRecorder())), "", time.Time{})
+		}
+
+		_, err := check("PUT", "If-Match")
+		Expect(err).To(BeNil())
+		_, err = check("PUT", "If-None-Match")
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_HTTP_PRECONDITION_FAILED))
+		fresh, err := check("GET", "If-None-Match")
+		Expect(err).To(BeNil())
+		Expect(fresh).To(BeTrue())
+	})
+})
+
+var _ = Describe("CheckMissingPreconditions", func() {
+	It("should match * against nothing", func() {
+		check := func(key string) errors.Error {
+			req := httptest.NewRequest("PUT", "/foo", nil)
+			req.Header.Set(key, "*")
+			return CheckMissingPreconditions(NewConnection(NewRequest(req), NewJsonResponse(httptest.NewRecorder())))
+		}
+
+		Expect(check("If-None-Match")).To(BeNil())
+		err := check("If-Match")
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_HTTP_PRECONDITION_FAILED))
+	})
+})
+
+var _ = Describe("matchETag", func() {
+	It("should compare entity tags weakly or strongly", func() {
+		Expect(matchETag(`W/"a"`, `"a"`, true)).To(BeTrue())
+		Expect(matchETag(`W/"a"`, `"a"`, false)).To(BeFalse())
+		Expect(matchETag(`"b", "a"`, `"a"`, false)).To(BeTrue())
+		Expect(matchETag(`*`, `"a"`, false)).To(BeTrue())
+		Expect(matchETag(`*`, ``, false)).To(BeTrue())
+		Expect(matchETag(`"a"`, ``, true)).To(BeFalse())
+	})
+})
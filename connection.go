@@ -1,6 +1,10 @@
 package lapi
 
 type Connection interface {
+	// ContainerAware gives request-scoped container,
+	// it is a child of application's container and it is disposed after response is sent
+	ContainerAware
+
 	// Request returns an instance of request
 	Request() Request
 
@@ -15,12 +19,13 @@ type Connection interface {
 }
 
 func NewConnection(request Request, response Response) Connection {
-	return &FactoryConnection{request, response}
+	return &FactoryConnection{request: request, response: response}
 }
 
 type FactoryConnection struct {
-	request  Request
-	response Response
+	request   Request
+	response  Response
+	container Container
 }
 
 func (c *FactoryConnection) Request() Request {
@@ -40,3 +45,12 @@ func (c *FactoryConnection) WithResponse(response Response) Connection {
 	c.response = response
 	return c
 }
+
+func (c *FactoryConnection) Container() Container {
+	return c.container
+}
+
+func (c *FactoryConnection) WithContainer(container Container) ContainerAware {
+	c.container = container
+	return c
+}
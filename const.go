@@ -9,35 +9,52 @@ const (
 	// whatever number if necessary
 
 	// App errors
-	ERR_ROUTER_NOT_DEFINED    = "0.001.001"
-	ERR_SERVER_CONFIG_MISSING = "0.001.002"
-	ERR_NO_HANDLER_FOUND      = "0.001.003"
-	ERR_INVALID_ARGUMENT      = "0.001.004"
-	ERR_CLONE_INVALID_TYPE    = "0.001.005"
-	ERR_STRUCT_INVALID_TYPE   = "0.001.005"
-	ERR_CONTAINER_NOT_DEFINED = "0.001.006"
-	ERR_RESCUER_NOT_DEFINED   = "0.001.007"
+	ERR_ROUTER_NOT_DEFINED       = "0.001.001"
+	ERR_SERVER_CONFIG_MISSING    = "0.001.002"
+	ERR_NO_HANDLER_FOUND         = "0.001.003"
+	ERR_INVALID_ARGUMENT         = "0.001.004"
+	ERR_CLONE_INVALID_TYPE       = "0.001.005"
+	ERR_STRUCT_INVALID_TYPE      = "0.001.005"
+	ERR_CONTAINER_NOT_DEFINED    = "0.001.006"
+	ERR_RESCUER_NOT_DEFINED      = "0.001.007"
+	ERR_DEPENDENCY_CHECK_FAILURE = "0.001.008"
 
 	// Router, http errors
-	ERR_HTTP_NOT_FOUND              = "0.002.001"
-	ERR_HTTP_BAD_REQUEST            = "0.002.002"
-	ERR_HTTP_INTERNAL_SERVER_ERROR  = "0.002.003"
-	ERR_HTTP_UNKNOWN_ERROR          = "0.002.004"
-	ERR_ROUTER_DUPLICATE_ROUTE_NAME = "0.002.005"
+	ERR_HTTP_NOT_FOUND                = "0.002.001"
+	ERR_HTTP_BAD_REQUEST              = "0.002.002"
+	ERR_HTTP_INTERNAL_SERVER_ERROR    = "0.002.003"
+	ERR_HTTP_UNKNOWN_ERROR            = "0.002.004"
+	ERR_ROUTER_DUPLICATE_ROUTE_NAME   = "0.002.005"
+	ERR_HTTP_METHOD_NOT_ALLOWED       = "0.002.006"
+	ERR_ROUTE_NOT_FOUND               = "0.002.007"
+	ERR_ROUTE_MISSING_PARAMETER       = "0.002.008"
+	ERR_ROUTE_INVALID_PARAMETER       = "0.002.009"
+	ERR_ROUTE_NOT_REVERSIBLE          = "0.002.010"
+	ERR_BIND_INVALID_TARGET           = "0.002.011"
+	ERR_VALIDATE_INVALID_RULE         = "0.002.012"
+	ERR_HTTP_NOT_ACCEPTABLE           = "0.002.013"
+	ERR_HTTP_REQUEST_ENTITY_TOO_LARGE = "0.002.014"
+	ERR_HTTP_PRECONDITION_FAILED      = "0.002.015"
 
 	// Request, Response, Body, Parser, Async errors
-	ERR_RESPONSE_ALREADY_SENT = "0.003.001"
-	ERR_NO_PARSER_FOUND       = "0.003.002"
-	ERR_CONTENT_TYPE_EMPTY    = "0.003.003"
-	ERR_NO_WRITER_FOUND       = "0.003.004"
-	ERR_PARSE_DECODE_FAILURE  = "0.003.005"
-	ERR_PARSE_ENCODE_FAILURE  = "0.003.006"
-	ERR_RESPONSE_IS_SENDING   = "0.003.007"
-	ERR_BODY_READER_MISSING   = "0.003.008"
-	ERR_BODY_READER_FAILURE   = "0.003.009"
-	ERR_BODY_WRITER_MISSING   = "0.003.010"
-	ERR_BODY_WRITER_FAILURE   = "0.003.011"
-	ERR_ASYNC_INVALID_TYPE    = "0.003.012"
+	ERR_RESPONSE_ALREADY_SENT     = "0.003.001"
+	ERR_NO_PARSER_FOUND           = "0.003.002"
+	ERR_CONTENT_TYPE_EMPTY        = "0.003.003"
+	ERR_NO_WRITER_FOUND           = "0.003.004"
+	ERR_PARSE_DECODE_FAILURE      = "0.003.005"
+	ERR_PARSE_ENCODE_FAILURE      = "0.003.006"
+	ERR_RESPONSE_IS_SENDING       = "0.003.007"
+	ERR_BODY_READER_MISSING       = "0.003.008"
+	ERR_BODY_READER_FAILURE       = "0.003.009"
+	ERR_BODY_WRITER_MISSING       = "0.003.010"
+	ERR_BODY_WRITER_FAILURE       = "0.003.011"
+	ERR_ASYNC_INVALID_TYPE        = "0.003.012"
+	ERR_MULTIPART_INVALID         = "0.003.013"
+	ERR_MULTIPART_LIMIT_EXCEEDED  = "0.003.014"
+	ERR_MULTIPART_STORAGE_FAILURE = "0.003.015"
+	ERR_STREAM_NOT_SUPPORTED      = "0.003.016"
+	ERR_STREAM_CLOSED             = "0.003.017"
+	ERR_BODY_UNSUPPORTED_ENCODING = "0.003.018"
 
 	// Container error
 	ERR_BIND_INVALID_INTERFACE         = "0.004.001"
@@ -52,9 +69,23 @@ const (
 	ERR_RESOLVE_NON_VALUES_RETURNED    = "0.004.010"
 	ERR_RESOLVE_INVALID_ARGUMENTS      = "0.004.011"
 	ERR_INJECT_INVALID_TARGET_TYPE     = "0.004.012"
+	ERR_RESOLVE_OUT_OF_SCOPE           = "0.004.013"
+	ERR_DISPOSE_FAILURE                = "0.004.014"
+	ERR_RESOLVE_CIRCULAR_DEPENDENCY    = "0.004.015"
+	ERR_RESOLVE_CONSTRUCTOR_FAILURE    = "0.004.016"
 
-	PRIORITY_DEFAULT     = 0
-	PRIORITY_SYSTEM_HOOK = 100
+	// Container's lifetimes
+	LIFETIME_SINGLETON = 1
+	LIFETIME_TRANSIENT = 2
+	LIFETIME_SCOPED    = 3
+
+	// Layout of placeholder <name:date>
+	PARAM_DATE_LAYOUT = "2006-01-02"
+
+	PRIORITY_DEFAULT          = 0
+	PRIORITY_SYSTEM_HOOK      = 100
+	PRIORITY_ETAG_HOOK        = 105
+	PRIORITY_COMPRESSION_HOOK = 110
 
 	PORT_HTTP  = 80
 	PORT_HTTPS = 443
@@ -64,10 +95,37 @@ const (
 
 	HEADER_CONTENT_TYPE = "content-type"
 	HEADER_LOCATION     = "location"
+	HEADER_ALLOW        = "allow"
+	HEADER_ACCEPT       = "accept"
+	HEADER_VARY         = "vary"
+	HEADER_CACHE        = "cache-control"
+	HEADER_LAST_EVENT   = "last-event-id"
+
+	HEADER_ETAG                = "etag"
+	HEADER_LAST_MODIFIED       = "last-modified"
+	HEADER_IF_MATCH            = "if-match"
+	HEADER_IF_NONE_MATCH       = "if-none-match"
+	HEADER_IF_MODIFIED_SINCE   = "if-modified-since"
+	HEADER_IF_UNMODIFIED_SINCE = "if-unmodified-since"
+
+	HEADER_ACCEPT_ENCODING  = "accept-encoding"
+	HEADER_CONTENT_ENCODING = "content-encoding"
+
+	ENCODING_GZIP     = "gzip"
+	ENCODING_DEFLATE  = "deflate"
+	ENCODING_IDENTITY = "identity"
+
+	// Responses smaller than this are not compressed by CompressionHook
+	COMPRESSION_MIN_SIZE = 1024
 
 	CONTENT_TYPE_JSON       = "application/json"
 	CONTENT_TYPE_XML        = "application/xml"
 	CONTENT_TYPE_TEXT       = "text/plain"
+	CONTENT_TYPE_YAML       = "application/yaml"
+	CONTENT_TYPE_FORM       = "application/x-www-form-urlencoded"
+	CONTENT_TYPE_MULTIPART  = "multipart/form-data"
+	CONTENT_TYPE_EVENTS     = "text/event-stream"
+	CONTENT_TYPE_PROBLEM    = "application/problem+json"
 	CONTENT_TYPE_DEFAULT    = CONTENT_TYPE_JSON
 	CONTENT_CHARSET_DEFAULT = "utf-8"
 )
@@ -3,6 +3,8 @@ package lapi
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/goline/errors"
@@ -11,6 +13,7 @@ import (
 // Container acts as a dependency-injection manager
 type Container interface {
 	Binder
+	Scoper
 	Resolver
 	Injector
 }
@@ -18,19 +21,68 @@ type Container interface {
 // Binder uses to bind a concrete to an abstract
 type Binder interface {
 	// Bind stores a concrete of an abstract, as default sharing is enable
+	// A pointer concrete is shared as singleton, a function concrete is called on every resolving
 	Bind(abstract interface{}, concrete interface{}) errors.Error
+
+	// BindSingleton stores a concrete which is resolved once,
+	// a function concrete is called on first resolving only
+	BindSingleton(abstract interface{}, concrete interface{}) errors.Error
+
+	// BindTransient stores a concrete which is resolved freshly every time,
+	// a pointer concrete is copied on every resolving
+	BindTransient(abstract interface{}, concrete interface{}) errors.Error
+
+	// BindScoped stores a concrete which is resolved once per scope.
+	// It could not be resolved from a root container
+	BindScoped(abstract interface{}, concrete interface{}) errors.Error
+
+	// BindNamed stores a concrete of an abstract under a name, such as "primary" and "replica".
+	// Lifetime of concrete follows Bind
+	BindNamed(name string, abstract interface{}, concrete interface{}) errors.Error
+
+	// BindTagged appends a concrete of an abstract to a group, such as "listeners".
+	// Lifetime of concrete follows Bind
+	BindTagged(tag string, abstract interface{}, concrete interface{}) errors.Error
+}
+
+// Scoper manages child containers, such as a container per request
+type Scoper interface {
+	// Scope returns a child container. It shares bindings of current container,
+	// while bindings made on child container are not visible to its parent
+	Scope() Container
+
+	// Dispose releases scoped concretes implementing Disposable in reverse order of creation
+	Dispose() errors.Error
+}
+
+// Disposable releases its resources when its scope is disposed
+type Disposable interface {
+	Dispose() error
 }
 
 // Resolver helps to resolve dependencies
 type Resolver interface {
-	// Resolve processes and returns a concrete of proposed abstract
+	// Resolve processes and returns a concrete of proposed abstract.
+	// When no args are given, parameters of a function concrete are resolved from container.
+	// A function concrete could return (T, error)
 	Resolve(abstract interface{}, args ...interface{}) (concrete interface{}, err errors.Error)
+
+	// ResolveNamed processes and returns a concrete bound under name
+	ResolveNamed(name string, abstract interface{}, args ...interface{}) (concrete interface{}, err errors.Error)
+
+	// ResolveTagged returns concretes of every implementation bound to tag, in order of binding
+	ResolveTagged(tag string, abstract interface{}) (concretes []interface{}, err errors.Error)
 }
 
 // Injector works as a tool to inject dependencies
 type Injector interface {
-	// Inject resolves target's dependencies
+	// Inject resolves target's dependencies. A field tagged with `inject:"*"` receives default concrete,
+	// `inject:"name"` receives named concrete, while a slice field receives concretes of tag `inject:"tag"`
 	Inject(target interface{}) errors.Error
+
+	// Check walks target's dependencies recursively without resolving them.
+	// It returns problems of missing, ambiguous, out of scope or circular bindings
+	Check(target interface{}) []string
 }
 
 // ContainerAware handles a container
@@ -47,37 +99,208 @@ func NewContainer() Container {
 }
 
 type FactoryContainer struct {
-	items *sync.Map
+	items  *sync.Map
+	parent *FactoryContainer
+
+	// instances caches resolved singleton and scoped concretes,
+	// creating holds a mutex per key, so a concrete is created once
+	instances   sync.Map
+	creating    sync.Map
+	disposables []Disposable
+	mu          sync.Mutex
 }
 
+// binding is a concrete stored with its lifetime
+type binding struct {
+	lifetime int
+	value    reflect.Value
+}
+
+// taggedBindings is a group of bindings, it is replaced as a whole on every BindTagged
+type taggedBindings []*binding
+
 func (c *FactoryContainer) Bind(abstract interface{}, concrete interface{}) errors.Error {
+	return c.bind("", abstract, concrete, c.lifetimeOf(concrete))
+}
+
+func (c *FactoryContainer) BindSingleton(abstract interface{}, concrete interface{}) errors.Error {
+	return c.bind("", abstract, concrete, LIFETIME_SINGLETON)
+}
+
+func (c *FactoryContainer) BindTransient(abstract interface{}, concrete interface{}) errors.Error {
+	return c.bind("", abstract, concrete, LIFETIME_TRANSIENT)
+}
+
+func (c *FactoryContainer) BindScoped(abstract interface{}, concrete interface{}) errors.Error {
+	return c.bind("", abstract, concrete, LIFETIME_SCOPED)
+}
+
+func (c *FactoryContainer) BindNamed(name string, abstract interface{}, concrete interface{}) errors.Error {
+	return c.bind(name, abstract, concrete, c.lifetimeOf(concrete))
+}
+
+func (c *FactoryContainer) BindTagged(tag string, abstract interface{}, concrete interface{}) errors.Error {
+	at, b, err := c.bindingOf(abstract, concrete, c.lifetimeOf(concrete))
+	if err != nil {
+		return err
+	}
+
+	key := c.tagKeyOf(at, tag)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var group taggedBindings
+	if v, ok := c.items.Load(key); ok == true {
+		group = v.(taggedBindings)
+	}
+	c.items.Store(key, append(group[:len(group):len(group)], b))
+	return nil
+}
+
+// lifetimeOf returns default lifetime used by Bind
+func (c *FactoryContainer) lifetimeOf(concrete interface{}) int {
+	if concrete != nil && reflect.TypeOf(concrete).Kind() == reflect.Func {
+		return LIFETIME_TRANSIENT
+	}
+
+	return LIFETIME_SINGLETON
+}
+
+func (c *FactoryContainer) Scope() Container {
+	return &FactoryContainer{items: new(sync.Map), parent: c}
+}
+
+func (c *FactoryContainer) Dispose() errors.Error {
+	c.mu.Lock()
+	disposables := c.disposables
+	c.disposables = nil
+	c.mu.Unlock()
+
+	var err errors.Error
+	for i := len(disposables) - 1; i >= 0; i-- {
+		if e := disposables[i].Dispose(); e != nil && err == nil {
+			err = errors.New(ERR_DISPOSE_FAILURE, "Unable to dispose scoped concrete").WithDebug(e.Error())
+		}
+	}
+	if c.parent != nil {
+		c.instances.Range(func(key, value interface{}) bool {
+			c.instances.Delete(key)
+			return true
+		})
+	}
+	return err
+}
+
+func (c *FactoryContainer) bind(name string, abstract interface{}, concrete interface{}, lifetime int) errors.Error {
+	at, b, err := c.bindingOf(abstract, concrete, lifetime)
+	if err != nil {
+		return err
+	}
+
+	c.items.Store(c.keyOf(at, name), b)
+	return nil
+}
+
+func (c *FactoryContainer) bindingOf(abstract interface{}, concrete interface{}, lifetime int) (reflect.Type, *binding, errors.Error) {
 	at, isInterface := c.interfaceOf(abstract)
 	if isInterface == nil {
-		return c.bindInterface(at, concrete)
+		b, err := c.bindInterface(at, concrete, lifetime)
+		return at, b, err
 	}
 
 	at, isStruct := c.structOf(abstract)
 	if isStruct == nil {
-		return c.bindStruct(at, concrete)
+		b, err := c.bindStruct(at, concrete, lifetime)
+		return at, b, err
 	}
 
-	return errors.New(ERR_BIND_INVALID_ARGUMENTS, "Binding error! Invalid arguments.")
+	return nil, nil, errors.New(ERR_BIND_INVALID_ARGUMENTS, "Binding error! Invalid arguments.")
 }
 
 func (c *FactoryContainer) Resolve(abstract interface{}, args ...interface{}) (concrete interface{}, err errors.Error) {
+	return c.resolve("", abstract, nil, args...)
+}
+
+func (c *FactoryContainer) ResolveNamed(name string, abstract interface{}, args ...interface{}) (concrete interface{}, err errors.Error) {
+	return c.resolve(name, abstract, nil, args...)
+}
+
+func (c *FactoryContainer) ResolveTagged(tag string, abstract interface{}) (concretes []interface{}, err errors.Error) {
+	return c.resolveTagged(tag, abstract, nil)
+}
+
+// resolve keeps path of abstracts being resolved to detect circular dependencies
+func (c *FactoryContainer) resolve(name string, abstract interface{}, path []string, args ...interface{}) (concrete interface{}, err errors.Error) {
 	at, isInterface := c.interfaceOf(abstract)
 	if isInterface == nil {
-		return c.resolveInterface(at, args...)
+		return c.resolveInterface(at, name, path, args...)
 	}
 
 	at, isStruct := c.structOf(abstract)
 	if isStruct == nil {
-		return c.resolveStruct(at, args...)
+		return c.resolveStruct(at, name, path, args...)
 	}
 
 	return nil, errors.New(ERR_RESOLVE_INVALID_ARGUMENTS, "Resolving error! Invalid arguments.")
 }
 
+// resolveTagged resolves bindings of tag from parent containers first, then from current container
+func (c *FactoryContainer) resolveTagged(tag string, abstract interface{}, path []string) ([]interface{}, errors.Error) {
+	at, err := c.abstractOf(abstract)
+	if err != nil {
+		return nil, err
+	}
+
+	key := c.tagKeyOf(at, tag)
+	containers := make([]*FactoryContainer, 0)
+	for container := c; container != nil; container = container.parent {
+		containers = append([]*FactoryContainer{container}, containers...)
+	}
+
+	concretes := make([]interface{}, 0)
+	for _, owner := range containers {
+		v, ok := owner.items.Load(key)
+		if ok == false {
+			continue
+		}
+
+		for i, b := range v.(taggedBindings) {
+			o, err := c.resolveBinding(fmt.Sprintf("%s#%d", key, i), b, owner, path)
+			if err != nil {
+				return nil, err
+			}
+			concretes = append(concretes, o)
+		}
+	}
+	return concretes, nil
+}
+
+// abstractOf returns type of an interface or a struct abstract
+func (c *FactoryContainer) abstractOf(abstract interface{}) (reflect.Type, errors.Error) {
+	if at, err := c.interfaceOf(abstract); err == nil {
+		return at, nil
+	}
+
+	if at, err := c.structOf(abstract); err == nil {
+		return at, nil
+	}
+
+	return nil, errors.New(ERR_RESOLVE_INVALID_ARGUMENTS, "Resolving error! Invalid arguments.")
+}
+
+// keyOf returns key of binding, named bindings are stored as "type#name"
+func (c *FactoryContainer) keyOf(at reflect.Type, name string) string {
+	if name == "" || name == "*" {
+		return at.String()
+	}
+
+	return fmt.Sprintf("%v#%s", at, name)
+}
+
+// tagKeyOf returns key of tagged bindings, they are stored as "type@tag"
+func (c *FactoryContainer) tagKeyOf(at reflect.Type, tag string) string {
+	return fmt.Sprintf("%v@%s", at, tag)
+}
+
 func (c *FactoryContainer) Inject(target interface{}) errors.Error {
 	t := reflect.TypeOf(target)
 	switch t.Kind() {
@@ -94,89 +317,315 @@ func (c *FactoryContainer) Inject(target interface{}) errors.Error {
 	v := reflect.ValueOf(target).Elem()
 	for i := 0; i < n; i++ {
 		sf := s.Field(i)
-		if _, ok := sf.Tag.Lookup("inject"); ok == false {
+		name, ok := sf.Tag.Lookup("inject")
+		if ok == false {
 			continue
 		}
 
-		if sf.Type.Kind() != reflect.Interface &&
-			sf.Type.Kind() != reflect.Struct && sf.Type.Kind() != reflect.Ptr {
+		f := v.Field(i)
+		if f.CanSet() == false {
 			continue
 		}
 
-		f := v.Field(i)
-		if f.CanSet() == false {
+		switch sf.Type.Kind() {
+		case reflect.Interface, reflect.Struct, reflect.Ptr:
+			o, err := c.ResolveNamed(name, sf.Type)
+			if err != nil {
+				return err
+			}
+			c.Inject(o)
+			f.Set(reflect.ValueOf(o))
+		case reflect.Slice:
+			if err := c.injectTagged(name, f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *FactoryContainer) Check(target interface{}) []string {
+	t := reflect.TypeOf(target)
+	if t == nil {
+		return nil
+	}
+
+	return c.checkStruct(t, t.String(), nil)
+}
+
+// checkStruct checks injectable fields of struct t, desc describes where t is injected
+func (c *FactoryContainer) checkStruct(t reflect.Type, desc string, path []string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	problems := make([]string, 0)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, ok := sf.Tag.Lookup("inject")
+		if ok == false || sf.PkgPath != "" {
 			continue
 		}
 
-		o, err := c.Resolve(sf.Type)
+		fieldDesc := fmt.Sprintf("%s.%s", desc, sf.Name)
+		switch sf.Type.Kind() {
+		case reflect.Interface, reflect.Struct, reflect.Ptr:
+			problems = append(problems, c.checkAbstract(sf.Type, name, fieldDesc, path)...)
+		case reflect.Slice:
+			problems = append(problems, c.checkTagged(sf.Type.Elem(), name, fieldDesc, path)...)
+		}
+	}
+	return problems
+}
+
+// checkAbstract checks binding of an abstract which is injected by name
+func (c *FactoryContainer) checkAbstract(t reflect.Type, name string, desc string, path []string) []string {
+	at, err := c.abstractOf(t)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v could not be resolved", desc, t)}
+	}
+
+	key := c.keyOf(at, name)
+	b, _, ok := c.lookup(key)
+	if ok == false {
+		if names := c.namesOf(at); key == at.String() && len(names) > 0 {
+			return []string{fmt.Sprintf("%s: %s is ambiguous, it is bound by names %s", desc, key, strings.Join(names, ", "))}
+		}
+		return []string{fmt.Sprintf("%s: %s is not bound yet", desc, key)}
+	}
+
+	return c.checkBinding(key, b, desc, path)
+}
+
+// checkTagged checks every binding of tag
+func (c *FactoryContainer) checkTagged(t reflect.Type, tag string, desc string, path []string) []string {
+	at, err := c.abstractOf(t)
+	if err != nil {
+		return nil
+	}
+
+	key := c.tagKeyOf(at, tag)
+	problems := make([]string, 0)
+	for container := c; container != nil; container = container.parent {
+		if v, ok := container.items.Load(key); ok == true {
+			for i, b := range v.(taggedBindings) {
+				problems = append(problems, c.checkBinding(fmt.Sprintf("%s#%d", key, i), b, desc, path)...)
+			}
+		}
+	}
+	return problems
+}
+
+// checkBinding checks lifetime of binding, then dependencies of its concrete
+func (c *FactoryContainer) checkBinding(key string, b *binding, desc string, path []string) []string {
+	for _, p := range path {
+		if p == key {
+			return []string{fmt.Sprintf("%s: circular dependency%s", desc, c.pathOf(path, key))}
+		}
+	}
+	path = append(path[:len(path):len(path)], key)
+
+	problems := make([]string, 0)
+	if b.lifetime == LIFETIME_SCOPED && c.parent == nil {
+		problems = append(problems, fmt.Sprintf("%s: %s is scoped, it must be resolved from a scope", desc, key))
+	}
+
+	t := b.value.Type()
+	if t.Kind() != reflect.Func {
+		return append(problems, c.checkStruct(t, fmt.Sprintf("%s -> %v", desc, t), path)...)
+	}
+
+	for i := 0; i < t.NumIn(); i++ {
+		argDesc := fmt.Sprintf("%s -> %s argument %d", desc, key, i)
+		switch t.In(i).Kind() {
+		case reflect.Interface, reflect.Struct, reflect.Ptr:
+			problems = append(problems, c.checkAbstract(t.In(i), "", argDesc, path)...)
+		default:
+			problems = append(problems, fmt.Sprintf("%s: %v could not be resolved", argDesc, t.In(i)))
+		}
+	}
+	if t.NumOut() > 0 {
+		problems = append(problems, c.checkStruct(t.Out(0), fmt.Sprintf("%s -> %v", desc, t.Out(0)), path)...)
+	}
+	return problems
+}
+
+// namesOf returns sorted names of bindings of at
+func (c *FactoryContainer) namesOf(at reflect.Type) []string {
+	prefix := at.String() + "#"
+	names := make([]string, 0)
+	for container := c; container != nil; container = container.parent {
+		container.items.Range(func(key, value interface{}) bool {
+			if k := key.(string); strings.HasPrefix(k, prefix) {
+				names = append(names, strings.TrimPrefix(k, prefix))
+			}
+			return true
+		})
+	}
+	sort.Strings(names)
+	return names
+}
+
+// injectTagged sets slice field f with concretes of tag
+func (c *FactoryContainer) injectTagged(tag string, f reflect.Value) errors.Error {
+	et := f.Type().Elem()
+	if et.Kind() != reflect.Interface && et.Kind() != reflect.Struct && et.Kind() != reflect.Ptr {
+		return nil
+	}
+
+	concretes, err := c.ResolveTagged(tag, et)
+	if err != nil {
+		return err
+	}
+
+	items := reflect.MakeSlice(f.Type(), 0, len(concretes))
+	for _, o := range concretes {
+		c.Inject(o)
+		item, err := c.valueOf(o, et, nil)
 		if err != nil {
 			return err
 		}
-		c.Inject(o)
-		f.Set(reflect.ValueOf(o))
+		items = reflect.Append(items, item)
 	}
+	f.Set(items)
 	return nil
 }
 
-func (c *FactoryContainer) bindInterface(at reflect.Type, concrete interface{}) errors.Error {
+func (c *FactoryContainer) bindInterface(at reflect.Type, concrete interface{}, lifetime int) (*binding, errors.Error) {
 	ct := reflect.TypeOf(concrete)
 	switch ct.Kind() {
 	case reflect.Func:
 	case reflect.Ptr:
 		if c.instanceOf(at, ct) == false {
-			return errors.New(ERR_BIND_NOT_IMPLEMENT_INTERFACE, fmt.Sprintf("%v is not an instance of %v", ct, at))
+			return nil, errors.New(ERR_BIND_NOT_IMPLEMENT_INTERFACE, fmt.Sprintf("%v is not an instance of %v", ct, at))
 		}
 	default:
-		return errors.New(ERR_BIND_INVALID_CONCRETE, fmt.Sprintf("Non-supported kind of concrete. Got %v", ct.Kind()))
+		return nil, errors.New(ERR_BIND_INVALID_CONCRETE, fmt.Sprintf("Non-supported kind of concrete. Got %v", ct.Kind()))
 	}
 
-	c.items.Store(at.String(), reflect.ValueOf(concrete))
-	return nil
+	return &binding{lifetime, reflect.ValueOf(concrete)}, nil
 }
 
-func (c *FactoryContainer) bindStruct(at reflect.Type, concrete interface{}) errors.Error {
+func (c *FactoryContainer) bindStruct(at reflect.Type, concrete interface{}, lifetime int) (*binding, errors.Error) {
 	ct, err := c.structOf(concrete)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if at.String() != ct.String() {
-		return errors.New(ERR_BIND_INVALID_STRUCT_CONCRETE, fmt.Sprintf("Expects %s. Got %s", at.String(), ct.String()))
+		return nil, errors.New(ERR_BIND_INVALID_STRUCT_CONCRETE, fmt.Sprintf("Expects %s. Got %s", at.String(), ct.String()))
 	}
 
-	c.items.Store(at.String(), reflect.ValueOf(concrete))
-	return nil
+	return &binding{lifetime, reflect.ValueOf(concrete)}, nil
 }
 
-func (c *FactoryContainer) resolveInterface(at reflect.Type, args ...interface{}) (concrete interface{}, err errors.Error) {
-	v, ok := c.items.Load(at.String())
+func (c *FactoryContainer) resolveInterface(at reflect.Type, name string, path []string, args ...interface{}) (concrete interface{}, err errors.Error) {
+	key := c.keyOf(at, name)
+	b, owner, ok := c.lookup(key)
 	if ok == false {
-		return nil, errors.New(ERR_RESOLVE_NOT_EXIST_ABSTRACT, fmt.Sprintf("%v is not bound yet", at))
+		return nil, errors.New(ERR_RESOLVE_NOT_EXIST_ABSTRACT, fmt.Sprintf("%s is not bound yet%s", key, c.pathOf(path, key)))
 	}
-	value := v.(reflect.Value)
 
-	switch value.Kind() {
-	case reflect.Func:
-		return c.resolveFunc(value, args...)
-	case reflect.Ptr:
-		return value.Interface(), nil
+	switch b.value.Kind() {
+	case reflect.Func, reflect.Ptr:
+		return c.resolveBinding(key, b, owner, path, args...)
 	default:
-		return nil, errors.New(ERR_RESOLVE_INVALID_CONCRETE, fmt.Sprintf("Type %v is not supported", value.Kind()))
+		return nil, errors.New(ERR_RESOLVE_INVALID_CONCRETE, fmt.Sprintf("Type %v is not supported", b.value.Kind()))
 	}
 }
 
-func (c *FactoryContainer) resolveStruct(at reflect.Type, args ...interface{}) (concrete interface{}, err errors.Error) {
-	v, ok := c.items.Load(at.String())
+func (c *FactoryContainer) resolveStruct(at reflect.Type, name string, path []string, args ...interface{}) (concrete interface{}, err errors.Error) {
+	key := c.keyOf(at, name)
+	b, owner, ok := c.lookup(key)
 	if ok == false {
-		return nil, errors.New(ERR_RESOLVE_NOT_EXIST_ABSTRACT, fmt.Sprintf("%v is not bound yet", at))
+		return nil, errors.New(ERR_RESOLVE_NOT_EXIST_ABSTRACT, fmt.Sprintf("%s is not bound yet%s", key, c.pathOf(path, key)))
 	}
-	value := v.(reflect.Value)
 
-	switch value.Kind() {
+	switch b.value.Kind() {
 	case reflect.Struct, reflect.Ptr:
-		return value.Interface(), nil
+		return c.resolveBinding(key, b, owner, path, args...)
+	default:
+		return nil, errors.New(ERR_RESOLVE_INVALID_CONCRETE, fmt.Sprintf("Type %v is not supported", b.value.Kind()))
+	}
+}
+
+// lookup finds binding of key in container, then in its parents
+func (c *FactoryContainer) lookup(key string) (*binding, *FactoryContainer, bool) {
+	for container := c; container != nil; container = container.parent {
+		if v, ok := container.items.Load(key); ok == true {
+			return v.(*binding), container, true
+		}
+	}
+	return nil, nil, false
+}
+
+// resolveBinding applies binding's lifetime. Singletons are cached in container owning binding,
+// scoped concretes are cached in resolving container
+func (c *FactoryContainer) resolveBinding(key string, b *binding, owner *FactoryContainer, path []string, args ...interface{}) (interface{}, errors.Error) {
+	for _, p := range path {
+		if p == key {
+			return nil, errors.New(ERR_RESOLVE_CIRCULAR_DEPENDENCY, fmt.Sprintf("Circular dependency detected%s", c.pathOf(path, key)))
+		}
+	}
+	path = append(path[:len(path):len(path)], key)
+
+	switch b.lifetime {
+	case LIFETIME_SINGLETON:
+		return owner.cached(key, b, false, path, args...)
+	case LIFETIME_SCOPED:
+		if c.parent == nil {
+			return nil, errors.New(ERR_RESOLVE_OUT_OF_SCOPE, fmt.Sprintf("%s is scoped, it must be resolved from a scope", key))
+		}
+		return c.cached(key, b, true, path, args...)
 	default:
-		return nil, errors.New(ERR_RESOLVE_INVALID_CONCRETE, fmt.Sprintf("Type %v is not supported", value.Kind()))
+		return c.create(b, true, path, args...)
+	}
+}
+
+func (c *FactoryContainer) cached(key string, b *binding, copy bool, path []string, args ...interface{}) (interface{}, errors.Error) {
+	if v, ok := c.instances.Load(key); ok == true {
+		return v, nil
+	}
+
+	m, _ := c.creating.LoadOrStore(key, new(sync.Mutex))
+	m.(*sync.Mutex).Lock()
+	defer m.(*sync.Mutex).Unlock()
+	if v, ok := c.instances.Load(key); ok == true {
+		return v, nil
+	}
+
+	v, err := c.create(b, copy, path, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	c.instances.Store(key, v)
+	if d, ok := v.(Disposable); ok == true && b.lifetime == LIFETIME_SCOPED {
+		c.mu.Lock()
+		c.disposables = append(c.disposables, d)
+		c.mu.Unlock()
+	}
+	return v, nil
+}
+
+// create builds a concrete, a pointer concrete is shallow copied if copy is true
+func (c *FactoryContainer) create(b *binding, copy bool, path []string, args ...interface{}) (interface{}, errors.Error) {
+	switch b.value.Kind() {
+	case reflect.Func:
+		return c.resolveFunc(b.value, path, args...)
+	case reflect.Ptr:
+		if copy == false || b.value.IsNil() {
+			return b.value.Interface(), nil
+		}
+
+		v := reflect.New(b.value.Type().Elem())
+		v.Elem().Set(b.value.Elem())
+		return v.Interface(), nil
+	default:
+		return b.value.Interface(), nil
 	}
 }
 
@@ -238,20 +687,81 @@ func (c *FactoryContainer) instanceOf(abstract reflect.Type, concrete reflect.Ty
 	}
 }
 
-func (c *FactoryContainer) resolveFunc(value reflect.Value, args ...interface{}) (concrete interface{}, err errors.Error) {
+func (c *FactoryContainer) resolveFunc(value reflect.Value, path []string, args ...interface{}) (concrete interface{}, err errors.Error) {
 	t := value.Type()
-	if len(args) != t.NumIn() {
-		return nil, errors.New(ERR_RESOLVE_INSUFFICIENT_ARGUMENTS, fmt.Sprintf("Expects to have %v input arguments. Got %v", t.NumIn(), len(args)))
-	}
-
 	in := make([]reflect.Value, t.NumIn())
-	for i, arg := range args {
-		in[i] = reflect.ValueOf(arg)
+	switch {
+	case len(args) == t.NumIn():
+		for i, arg := range args {
+			in[i] = reflect.ValueOf(arg)
+		}
+	case len(args) == 0:
+		for i := 0; i < t.NumIn(); i++ {
+			if in[i], err = c.resolveArgument(t.In(i), path); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, errors.New(ERR_RESOLVE_INSUFFICIENT_ARGUMENTS, fmt.Sprintf("Expects to have %v input arguments. Got %v", t.NumIn(), len(args)))
 	}
 
 	out := value.Call(in)
 	if len(out) == 0 {
 		return nil, errors.New(ERR_RESOLVE_NON_VALUES_RETURNED, "Expects to have at least 1 value returned. Got 0")
 	}
+
+	if len(out) == 2 && t.Out(1) == errorType && out[1].IsNil() == false {
+		if e, ok := out[1].Interface().(errors.Error); ok == true {
+			return nil, e
+		}
+		return nil, errors.New(ERR_RESOLVE_CONSTRUCTOR_FAILURE, fmt.Sprintf("Unable to construct %s", path[len(path)-1])).
+			WithDebug(out[1].Interface().(error).Error())
+	}
 	return out[0].Interface(), nil
 }
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// resolveArgument resolves a parameter of function concrete from container
+func (c *FactoryContainer) resolveArgument(t reflect.Type, path []string) (reflect.Value, errors.Error) {
+	switch t.Kind() {
+	case reflect.Interface, reflect.Struct, reflect.Ptr:
+	default:
+		return reflect.Value{}, errors.New(ERR_RESOLVE_INVALID_ARGUMENTS, fmt.Sprintf("Unable to resolve argument of type %v%s", t, c.pathOf(path, t.String())))
+	}
+
+	o, err := c.resolve("", t, path)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return c.valueOf(o, t, path)
+}
+
+// valueOf converts a concrete to a value of type t, a pointer is dereferenced or taken when needed
+func (c *FactoryContainer) valueOf(o interface{}, t reflect.Type, path []string) (reflect.Value, errors.Error) {
+	v := reflect.ValueOf(o)
+	switch {
+	case v.IsValid() == false:
+		return reflect.Zero(t), nil
+	case v.Type().AssignableTo(t):
+		return v, nil
+	case v.Kind() == reflect.Ptr && v.Type().Elem().AssignableTo(t):
+		return v.Elem(), nil
+	case t.Kind() == reflect.Ptr && v.Kind() == reflect.Struct && v.Type().AssignableTo(t.Elem()):
+		p := reflect.New(t.Elem())
+		p.Elem().Set(v)
+		return p, nil
+	default:
+		return reflect.Value{}, errors.New(ERR_RESOLVE_INVALID_CONCRETE, fmt.Sprintf("%v is not assignable to %v%s", v.Type(), t, c.pathOf(path, t.String())))
+	}
+}
+
+// pathOf describes dependency path which leads to key, such as " (A -> B -> C)"
+func (c *FactoryContainer) pathOf(path []string, key string) string {
+	if len(path) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" (%s -> %s)", strings.Join(path, " -> "), key)
+}
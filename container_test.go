@@ -1,12 +1,15 @@
 package lapi
 
 import (
+	"fmt"
 	"reflect"
 
 	"github.com/goline/errors"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var _ = Describe("Container", func() {
@@ -220,15 +223,231 @@ var _ = Describe("FactoryContainer", func() {
 	})
 
 	It("instanceOf should return false", func() {
-		c := &FactoryContainer{new(sync.Map)}
+		c := &FactoryContainer{items: new(sync.Map)}
 		b := c.instanceOf(reflect.TypeOf("a_string"), reflect.TypeOf((*Bag)(nil)))
 		Expect(b).To(BeFalse())
 	})
 
 	It("instanceOf should return false (ConcreteTypeNotSupport)", func() {
-		c := &FactoryContainer{new(sync.Map)}
+		c := &FactoryContainer{items: new(sync.Map)}
 		i, _ := c.interfaceOf((*Bag)(nil))
 		b := c.instanceOf(i, reflect.TypeOf("a_string"))
 		Expect(b).To(BeFalse())
 	})
+
+	It("BindSingleton should call function concrete once", func() {
+		c := NewContainer()
+		calls := 0
+		c.BindSingleton((*InjectBazer)(nil), func() InjectBazer {
+			calls++
+			return &InjectBaz{}
+		})
+		b1, err := c.Resolve((*InjectBazer)(nil))
+		Expect(err).To(BeNil())
+		b2, _ := c.Scope().Resolve((*InjectBazer)(nil))
+		Expect(b1 == b2).To(BeTrue())
+		Expect(calls).To(Equal(1))
+	})
+
+	It("BindTransient should copy pointer concrete on every resolving", func() {
+		c := NewContainer()
+		c.BindTransient((*InjectFooer)(nil), &InjectFoo{})
+		f1, _ := c.Resolve((*InjectFooer)(nil))
+		f2, _ := c.Resolve((*InjectFooer)(nil))
+		Expect(f1 == f2).To(BeFalse())
+	})
+
+	It("BindScoped should resolve once per scope", func() {
+		c := NewContainer()
+		c.BindScoped((*InjectFooer)(nil), func() InjectFooer { return &InjectFoo{} })
+		_, err := c.Resolve((*InjectFooer)(nil))
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_RESOLVE_OUT_OF_SCOPE))
+
+		s1, s2 := c.Scope(), c.Scope()
+		f1, err := s1.Resolve((*InjectFooer)(nil))
+		Expect(err).To(BeNil())
+		f2, _ := s1.Resolve((*InjectFooer)(nil))
+		f3, _ := s2.Resolve((*InjectFooer)(nil))
+		Expect(f1 == f2).To(BeTrue())
+		Expect(f1 == f3).To(BeFalse())
+	})
+
+	It("Scope should not expose its bindings to parent", func() {
+		c := NewContainer()
+		s := c.Scope()
+		s.Bind((*InjectBazer)(nil), &InjectBaz{})
+		_, err := s.Resolve((*InjectBazer)(nil))
+		Expect(err).To(BeNil())
+		_, err = c.Resolve((*InjectBazer)(nil))
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("Dispose should release scoped concretes in reverse order", func() {
+		disposed := make([]string, 0)
+		c := NewContainer()
+		c.BindScoped((*InjectFooer)(nil), func() InjectFooer { return &disposableFoo{"foo", &disposed} })
+		c.BindScoped((*InjectBazer)(nil), func() InjectBazer { return &disposableFoo{"baz", &disposed} })
+		s := c.Scope()
+		s.Resolve((*InjectFooer)(nil))
+		s.Resolve((*InjectBazer)(nil))
+		Expect(s.Dispose()).To(BeNil())
+		Expect(disposed).To(Equal([]string{"baz", "foo"}))
+	})
+
+	It("Resolve should create singleton and scoped concretes once under concurrency", func() {
+		var singletons, scopeds int32
+		c := NewContainer()
+		c.BindSingleton((*InjectBazer)(nil), func() InjectBazer {
+			atomic.AddInt32(&singletons, 1)
+			time.Sleep(time.Millisecond)
+			return &InjectBaz{}
+		})
+		c.BindScoped((*InjectFooer)(nil), func() InjectFooer {
+			atomic.AddInt32(&scopeds, 1)
+			time.Sleep(time.Millisecond)
+			return &disposableFoo{"foo", new([]string)}
+		})
+
+		s := c.Scope()
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.Resolve((*InjectBazer)(nil))
+				s.Resolve((*InjectFooer)(nil))
+			}()
+		}
+		wg.Wait()
+		Expect(atomic.LoadInt32(&singletons)).To(Equal(int32(1)))
+		Expect(atomic.LoadInt32(&scopeds)).To(Equal(int32(1)))
+		Expect(len(s.(*FactoryContainer).disposables)).To(Equal(1))
+	})
+
+	It("Resolve should wire arguments of function concrete from container", func() {
+		c := NewContainer()
+		c.Bind((*InjectBazer)(nil), &InjectBaz{})
+		c.Bind((*InjectFooer)(nil), func(baz InjectBazer) InjectFooer { return &InjectFoo{baz} })
+		f, err := c.Resolve((*InjectFooer)(nil))
+		Expect(err).To(BeNil())
+		Expect(f.(*InjectFoo).Baz).NotTo(BeNil())
+	})
+
+	It("Resolve should return error code ERR_RESOLVE_NOT_EXIST_ABSTRACT with dependency path", func() {
+		c := NewContainer()
+		c.Bind((*InjectFooer)(nil), func(baz InjectBazer) InjectFooer { return &InjectFoo{baz} })
+		_, err := c.Resolve((*InjectFooer)(nil))
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_RESOLVE_NOT_EXIST_ABSTRACT))
+		Expect(err.Message()).To(ContainSubstring("lapi.InjectFooer -> lapi.InjectBazer"))
+	})
+
+	It("Resolve should return error code ERR_RESOLVE_CIRCULAR_DEPENDENCY", func() {
+		c := NewContainer()
+		c.Bind((*InjectFooer)(nil), func(baz InjectBazer) InjectFooer { return &InjectFoo{baz} })
+		c.BindSingleton((*InjectBazer)(nil), func(foo InjectFooer) InjectBazer { return &InjectBaz{} })
+		_, err := c.Resolve((*InjectFooer)(nil))
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_RESOLVE_CIRCULAR_DEPENDENCY))
+		Expect(err.Message()).To(ContainSubstring("lapi.InjectFooer -> lapi.InjectBazer -> lapi.InjectFooer"))
+	})
+
+	It("Resolve should return error of function concrete returning (T, error)", func() {
+		c := NewContainer()
+		c.Bind((*InjectBazer)(nil), func() (InjectBazer, error) { return nil, fmt.Errorf("no baz") })
+		_, err := c.Resolve((*InjectBazer)(nil))
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_RESOLVE_CONSTRUCTOR_FAILURE))
+
+		c.Bind((*InjectBazer)(nil), func() (InjectBazer, error) { return &InjectBaz{}, nil })
+		b, err := c.Resolve((*InjectBazer)(nil))
+		Expect(err).To(BeNil())
+		Expect(b.(InjectBazer).Baz()).To(Equal("Baz.."))
+	})
+
+	It("ResolveNamed should return concrete bound under name", func() {
+		c := NewContainer()
+		primary, replica := &InjectBaz{}, &InjectBaz{}
+		c.BindNamed("primary", (*InjectBazer)(nil), primary)
+		c.BindNamed("replica", (*InjectBazer)(nil), replica)
+		b, err := c.ResolveNamed("replica", (*InjectBazer)(nil))
+		Expect(err).To(BeNil())
+		Expect(b == replica).To(BeTrue())
+
+		_, err = c.Resolve((*InjectBazer)(nil))
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_RESOLVE_NOT_EXIST_ABSTRACT))
+	})
+
+	It("ResolveTagged should return every concrete of tag", func() {
+		c := NewContainer()
+		c.BindTagged("listeners", (*InjectBazer)(nil), &InjectBaz{})
+		c.BindTagged("listeners", (*InjectBazer)(nil), func() InjectBazer { return &disposableFoo{name: "baz"} })
+		s := c.Scope()
+		s.BindTagged("listeners", (*InjectBazer)(nil), &disposableFoo{name: "scoped"})
+		concretes, err := s.ResolveTagged("listeners", (*InjectBazer)(nil))
+		Expect(err).To(BeNil())
+		Expect(len(concretes)).To(Equal(3))
+		Expect(concretes[1].(InjectBazer).Baz()).To(Equal("baz"))
+		Expect(concretes[2].(InjectBazer).Baz()).To(Equal("scoped"))
+
+		concretes, err = c.ResolveTagged("listeners", (*InjectBazer)(nil))
+		Expect(err).To(BeNil())
+		Expect(len(concretes)).To(Equal(2))
+	})
+
+	It("Inject should inject named and tagged concretes", func() {
+		c := NewContainer()
+		replica := &InjectBaz{}
+		c.BindNamed("replica", (*InjectBazer)(nil), replica)
+		c.BindTagged("listeners", (*InjectBazer)(nil), &InjectBaz{})
+		c.BindTagged("listeners", (*InjectBazer)(nil), &InjectBaz{})
+		target := &injectNamed{}
+		Expect(c.Inject(target)).To(BeNil())
+		Expect(target.Replica == replica).To(BeTrue())
+		Expect(len(target.Listeners)).To(Equal(2))
+	})
+
+	It("Check should report missing, ambiguous and circular dependencies", func() {
+		c := NewContainer()
+		c.BindNamed("primary", (*InjectBazer)(nil), &InjectBaz{})
+		c.Bind((*InjectFooer)(nil), &InjectFoo{})
+		problems := c.Check(&injectChecked{})
+		Expect(problems).To(Equal([]string{
+			"*lapi.injectChecked.Foo -> *lapi.InjectFoo.Baz: lapi.InjectBazer is ambiguous, it is bound by names primary",
+			"*lapi.injectChecked.Err: errors.Error is not bound yet",
+		}))
+
+		c.Bind((*InjectBazer)(nil), func(foo InjectFooer) InjectBazer { return &InjectBaz{} })
+		c.Bind((*errors.Error)(nil), &errors.FactoryError{})
+		problems = c.Check(&injectChecked{})
+		Expect(len(problems)).To(Equal(1))
+		Expect(problems[0]).To(ContainSubstring("circular dependency (lapi.InjectFooer -> lapi.InjectBazer -> lapi.InjectFooer)"))
+
+		c.Bind((*InjectBazer)(nil), &InjectBaz{})
+		Expect(c.Check(&injectChecked{})).To(BeEmpty())
+	})
 })
+
+type injectChecked struct {
+	Foo InjectFooer  `inject:"*"`
+	Err errors.Error `inject:"*"`
+}
+
+type injectNamed struct {
+	Replica   InjectBazer   `inject:"replica"`
+	Listeners []InjectBazer `inject:"listeners"`
+}
+
+type disposableFoo struct {
+	name     string
+	disposed *[]string
+}
+
+func (f *disposableFoo) Foo() string { return f.name }
+func (f *disposableFoo) Baz() string { return f.name }
+func (f *disposableFoo) Dispose() error {
+	*f.disposed = append(*f.disposed, f.name)
+	return nil
+}
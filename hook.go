@@ -1,12 +1,23 @@
 package lapi
 
-import "github.com/goline/errors"
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/goline/errors"
+)
 
 // Hook acts as a middleware of processing request
 type Hook interface {
 	// Since v1.0.14
 	// Hook will become an empty interface
-	// User should implement either BootableHook or HaltableHook
+	// User should implement either BootableHook, HaltableHook or MiddlewareHook
 }
 
 // BootableHook allows to register hook to be executed before handler runs
@@ -23,6 +34,67 @@ type HaltableHook interface {
 	TearDown(connection Connection, result interface{}, err errors.Error) errors.Error
 }
 
+// SequentialHook marks a hook which must not run concurrently with other hooks
+// of the same priority. It runs alone, in registration order
+type SequentialHook interface {
+	// Sequential returns true if hook must run alone
+	Sequential() bool
+}
+
+// runHooks runs f on route's hooks in priority order. Hooks of the same priority
+// run in parallel, except SequentialHook ones. All hooks run one after another
+// in registration order if route's hooks are sequential
+func runHooks(route Route, f SliceFunc) {
+	hooks := route.Hooks()
+	if route.SequentialHooks() == true {
+		Sequential(hooks, f)
+		return
+	}
+
+	for _, i := range sortedIndexes(hooks) {
+		batch := new(Slice)
+		for _, item := range hooks[i].All() {
+			if hook, ok := item.(SequentialHook); ok == true && hook.Sequential() == true {
+				batch.Run(f)
+				batch = new(Slice)
+				f(item)
+			} else {
+				batch.Append(item)
+			}
+		}
+		batch.Run(f)
+	}
+}
+
+// MiddlewareHook wraps handler in onion style. Middleware hooks are composed
+// in priority order, the first one is the outermost. Calling next runs inner
+// middleware hooks and handler, then hook is free to inspect or rewrite the outcome
+type MiddlewareHook interface {
+	// Handle runs hook around next, it returns result and error of handling request
+	Handle(connection Connection, next func() (interface{}, errors.Error)) (interface{}, errors.Error)
+}
+
+// chain composes route's middleware hooks around handler
+func chain(hooks map[int]*Slice, handler Handler, c Connection) func() (interface{}, errors.Error) {
+	next := func() (interface{}, errors.Error) {
+		return handler.Handle(c)
+	}
+
+	items := Sorted(hooks)
+	for i := len(items) - 1; i >= 0; i-- {
+		hook, ok := items[i].(MiddlewareHook)
+		if ok == false {
+			continue
+		}
+
+		inner := next
+		next = func() (interface{}, errors.Error) {
+			return hook.Handle(c, inner)
+		}
+	}
+	return next
+}
+
 // SystemHook acts as mandatory hook
 type SystemHook struct{}
 
@@ -56,3 +128,190 @@ func (h *ParserHook) SetUp(c Connection) errors.Error {
 	c.Response().Body().WithParser(parser)
 	return nil
 }
+
+// MultiParserHook registers every built-in parser (JSON, XML, form and text) on request's
+// and response's bodies, so contents are decoded and encoded by their content-type
+type MultiParserHook struct{}
+
+func (h *MultiParserHook) SetUp(c Connection) errors.Error {
+	for _, parser := range parsers() {
+		c.Request().Body().WithParser(parser)
+		c.Response().Body().WithParser(parser)
+	}
+	return nil
+}
+
+// NegotiationHook picks response's content-type from request's Accept header,
+// among parsers which are registered on response's body, wildcard parsers are not offered. Current content-type is preferred on ties.
+// It returns ERR_HTTP_NOT_ACCEPTABLE if none of them is acceptable, negotiation is skipped if no parsers are registered
+type NegotiationHook struct{}
+
+func (h *NegotiationHook) SetUp(c Connection) errors.Error {
+	addVary(c.Response().Header(), "Accept")
+	accept, ok := c.Request().Header().Get(HEADER_ACCEPT)
+	if ok == false || accept == "" {
+		return nil
+	}
+
+	body := c.Response().Body()
+	offers := make([]string, 0)
+	if body.ContentType() != "" {
+		if _, ok := body.Parser(body.ContentType()); ok == true {
+			offers = append(offers, body.ContentType())
+		}
+	}
+	for _, contentType := range body.ContentTypes() {
+		// wildcard parsers could not be written as response's content-type
+		if strings.Contains(contentType, "*") == false {
+			offers = append(offers, contentType)
+		}
+	}
+	if len(offers) == 0 {
+		return nil
+	}
+
+	contentType, ok := negotiate(accept, offers)
+	if ok == false {
+		return errors.New(ERR_HTTP_NOT_ACCEPTABLE, fmt.Sprintf("None of %s is acceptable", accept)).WithStatus(http.StatusNotAcceptable)
+	}
+
+	body.WithContentType(contentType)
+	return nil
+}
+
+// Priority implements Prioritizer interface, negotiation runs after parsers are registered
+func (h *NegotiationHook) Priority() int {
+	return PRIORITY_SYSTEM_HOOK
+}
+
+// Sequential implements SequentialHook interface, as it reads parsers and content-type of response's body
+// which are set up by hooks of the same priority, such as ParserHook registered before it
+func (h *NegotiationHook) Sequential() bool {
+	return true
+}
+
+// compressedContentTypes are already compressed, so CompressionHook skips them
+var compressedContentTypes = []string{
+	"image/", "video/", "audio/", "font/woff",
+	"application/zip", "application/gzip", "application/x-gzip", "application/x-bzip2",
+	"application/x-7z-compressed", "application/x-rar-compressed", "application/pdf",
+}
+
+// CompressionHook compresses response's body with gzip or deflate, picked from Accept-Encoding header.
+// It runs after SystemHook has written handler's result, so error responses are not compressed.
+// Strong ETag is turned into weak one as compressed content differs from identity content
+type CompressionHook struct {
+	// MinSize is the minimum size of body to be compressed, COMPRESSION_MIN_SIZE is used if 0
+	MinSize int
+}
+
+func (h *CompressionHook) TearDown(c Connection, result interface{}, err errors.Error) errors.Error {
+	if err != nil || c.Response().IsSent() == true {
+		return nil
+	}
+
+	header := c.Response().Header()
+	addVary(header, "Accept-Encoding")
+	if header.Has(HEADER_CONTENT_ENCODING) == true {
+		return nil
+	}
+
+	body := c.Response().Body()
+	min := h.MinSize
+	if min <= 0 {
+		min = COMPRESSION_MIN_SIZE
+	}
+	if len(body.Bytes()) < min || h.isCompressed(body.ContentType()) == true {
+		return nil
+	}
+
+	accept, _ := c.Request().Header().Get(HEADER_ACCEPT_ENCODING)
+	encoding, ok := negotiateEncoding(accept)
+	if ok == false {
+		return nil
+	}
+
+	data, e := h.compress(encoding, body.Bytes())
+	if e != nil {
+		return errors.New(ERR_BODY_WRITER_FAILURE, fmt.Sprintf("Unable to compress content with %s", encoding)).WithDebug(e.Error())
+	}
+
+	header.Set(HEADER_CONTENT_ENCODING, encoding)
+	if etag, ok := header.Get(HEADER_ETAG); ok == true && strings.HasPrefix(etag, "W/") == false {
+		// strong ETag describes identity content, compressed content is only semantically equivalent
+		header.Set(HEADER_ETAG, "W/"+etag)
+	}
+	return body.Write(data)
+}
+
+// Priority implements Prioritizer interface, compression runs after SystemHook
+func (h *CompressionHook) Priority() int {
+	return PRIORITY_COMPRESSION_HOOK
+}
+
+func (h *CompressionHook) isCompressed(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range compressedContentTypes {
+		if strings.HasPrefix(contentType, prefix) == true {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *CompressionHook) compress(encoding string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	if encoding == ENCODING_GZIP {
+		w = gzip.NewWriter(&buf)
+	} else {
+		w = zlib.NewWriter(&buf)
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ETagHook sets ETag header of response, which is computed from response's body unless handler sets one.
+// Conditional GET and HEAD requests are answered with 304 Not Modified or 412 Precondition Failed,
+// Last-Modified header set by handler is honored as well. Handlers of unsafe methods should check
+// preconditions before changing resource, see CheckPreconditions
+type ETagHook struct {
+	// Weak lets computed ETag be weak, such as W/"..."
+	Weak bool
+}
+
+func (h *ETagHook) TearDown(c Connection, result interface{}, err errors.Error) errors.Error {
+	response := c.Response()
+	if err != nil || response.IsSent() == true || response.Status() < 200 || response.Status() >= 300 {
+		return nil
+	}
+
+	etag, ok := response.Header().Get(HEADER_ETAG)
+	method := c.Request().Method()
+	if ok == false && (method == http.MethodGet || method == http.MethodHead) {
+		etag = computeETag(response.Body().Bytes(), h.Weak)
+		response.Header().Set(HEADER_ETAG, etag)
+	}
+
+	if method != http.MethodGet && method != http.MethodHead {
+		return nil
+	}
+
+	var lastModified time.Time
+	if value, ok := response.Header().Get(HEADER_LAST_MODIFIED); ok == true {
+		lastModified, _ = http.ParseTime(value)
+	}
+	_, e := CheckPreconditions(c, etag, lastModified)
+	return e
+}
+
+// Priority implements Prioritizer interface, ETag is computed after SystemHook, before compression
+func (h *ETagHook) Priority() int {
+	return PRIORITY_ETAG_HOOK
+}
@@ -0,0 +1,102 @@
+package lapi
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goline/errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type hookLargeHandler struct{}
+
+func (h *hookLargeHandler) Handle(c Connection) (interface{}, errors.Error) {
+	return map[string]string{"foo": strings.Repeat("bar", 500)}, nil
+}
+
+func serveCompressed(uri string, acceptEncoding string) *httptest.ResponseRecorder {
+	app := NewApp()
+	app.Router().Get("/large", &hookLargeHandler{}).
+		WithHook(new(SystemHook)).WithHook(new(ParserHook)).WithHook(new(CompressionHook))
+	app.Router().Get("/small", &appHandler{}).
+		WithHook(new(SystemHook)).WithHook(new(ParserHook)).WithHook(new(CompressionHook))
+	app.Run()
+
+	req := httptest.NewRequest("GET", uri, nil)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	rw := httptest.NewRecorder()
+	app.ServeHTTP(rw, req)
+	return rw
+}
+
+var _ = Describe("CompressionHook", func() {
+	It("should compress body with gzip or deflate", func() {
+		for _, encoding := range []string{"gzip", "deflate"} {
+			rw := serveCompressed("/large", "br, "+encoding)
+			Expect(rw.Header().Get("Content-Encoding")).To(Equal(encoding))
+			Expect(rw.Header().Get("Vary")).To(Equal("Accept-Encoding"))
+
+			var r io.Reader
+			var err error
+			if encoding == "gzip" {
+				r, err = gzip.NewReader(rw.Body)
+			} else {
+				r, err = zlib.NewReader(rw.Body)
+			}
+			Expect(err).To(BeNil())
+			body, _ := ioutil.ReadAll(r)
+			Expect(string(body)).To(Equal(`{"foo":"` + strings.Repeat("bar", 500) + `"}`))
+		}
+	})
+
+	It("should not compress small body or unaccepted encodings", func() {
+		for uri, accept := range map[string]string{"/small": "gzip", "/large": "gzip;q=0, br"} {
+			rw := serveCompressed(uri, accept)
+			Expect(rw.Header().Get("Content-Encoding")).To(BeEmpty())
+			Expect(rw.Body.String()).To(HavePrefix(`{"foo":`))
+		}
+	})
+
+	It("should weaken strong ETag of compressed content", func() {
+		app := NewApp()
+		app.Router().Get("/large", &hookLargeHandler{}).
+			WithHook(new(SystemHook)).WithHook(new(ParserHook)).WithHook(new(ETagHook)).WithHook(new(CompressionHook))
+		app.Run()
+
+		etags := make(map[string]string)
+		for _, encoding := range []string{"gzip", "identity"} {
+			req := httptest.NewRequest("GET", "/large", nil)
+			req.Header.Set("Accept-Encoding", encoding)
+			rw := httptest.NewRecorder()
+			app.ServeHTTP(rw, req)
+			etags[encoding] = rw.Header().Get("ETag")
+		}
+		Expect(etags["identity"]).To(HavePrefix(`"`))
+		Expect(etags["gzip"]).To(Equal("W/" + etags["identity"]))
+	})
+
+	It("should skip already compressed content types", func() {
+		Expect(new(CompressionHook).isCompressed("image/png")).To(BeTrue())
+		Expect(new(CompressionHook).isCompressed(CONTENT_TYPE_JSON)).To(BeFalse())
+	})
+})
+
+var _ = Describe("negotiateEncoding", func() {
+	It("should prefer gzip on ties and respect wildcard", func() {
+		encoding, ok := negotiateEncoding("deflate, gzip")
+		Expect(ok).To(BeTrue())
+		Expect(encoding).To(Equal("gzip"))
+
+		encoding, ok = negotiateEncoding("gzip;q=0.5, *")
+		Expect(ok).To(BeTrue())
+		Expect(encoding).To(Equal("deflate"))
+
+		_, ok = negotiateEncoding("identity")
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -1,8 +1,13 @@
 package lapi
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/goline/errors"
 )
@@ -13,6 +18,30 @@ type Loader interface {
 	Load(app App)
 }
 
+// ClosableLoader allows loader to release its resources when application shuts down
+type ClosableLoader interface {
+	// Close runs when application is shutting down,
+	// loaders are closed in reverse priority order
+	Close(app App) errors.Error
+}
+
+// BlockingLoader is a loader which blocks until application shuts down, such as ServerLoader.
+// Run starts blocking loaders concurrently, once other loaders are loaded and routes are checked
+type BlockingLoader interface {
+	// Blocking tells whether Load blocks until application shuts down
+	Blocking() bool
+}
+
+// serverShutdownTimeout is used when server.shutdown_timeout is not configured
+const serverShutdownTimeout = 30 * time.Second
+
+// ServerLoader runs http server. It reads following configuration:
+//
+//	server.address          address to listen on, such as :8080
+//	server.read_timeout     maximum duration for reading request, such as 5s
+//	server.write_timeout    maximum duration before timing out writes of response
+//	server.idle_timeout     maximum duration to wait for next request of keep-alive connection
+//	server.shutdown_timeout maximum duration to wait for in-flight requests on SIGINT, SIGTERM
 type ServerLoader struct {
 	PriorityAware
 }
@@ -20,12 +49,73 @@ type ServerLoader struct {
 func (l *ServerLoader) Load(app App) {
 	PanicOnError(app.Container().Inject(app.Rescuer()))
 
-	http.Handle("/", app)
-	if address, ok := app.Config().GetString("server.address"); ok {
-		PanicOnError(http.ListenAndServe(address, nil))
-	} else {
+	address, ok := app.Config().GetString("server.address")
+	if ok == false {
 		panic(errors.New(ERR_SERVER_CONFIG_MISSING, fmt.Sprint("Server configuration is missing")))
 	}
+
+	server := app.Server()
+	if server == nil {
+		server = &http.Server{}
+		app.WithServer(server)
+	}
+	server.Addr = address
+	server.Handler = app
+	server.ReadTimeout = l.duration(app.Config(), "server.read_timeout", server.ReadTimeout)
+	server.WriteTimeout = l.duration(app.Config(), "server.write_timeout", server.WriteTimeout)
+	server.IdleTimeout = l.duration(app.Config(), "server.idle_timeout", server.IdleTimeout)
+
+	var shutdownErr error
+	shutting := make(chan struct{})
+	stopped := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		defer close(stopped)
+		if _, ok := <-signals; ok == false {
+			return
+		}
+		close(shutting)
+
+		timeout := l.duration(app.Config(), "server.shutdown_timeout", serverShutdownTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		shutdownErr = app.Shutdown(ctx)
+	}()
+
+	err := server.ListenAndServe()
+	signal.Stop(signals)
+	select {
+	case <-shutting:
+		// wait for in-flight requests and closers
+		<-stopped
+		PanicOnError(shutdownErr)
+	default:
+		close(signals)
+	}
+
+	if err != http.ErrServerClosed {
+		PanicOnError(err)
+	}
+}
+
+// Blocking implements BlockingLoader interface, as server runs until shutdown
+func (l *ServerLoader) Blocking() bool {
+	return true
+}
+
+// duration reads a duration such as "5s", or a number of seconds
+func (l *ServerLoader) duration(config Bag, key string, value time.Duration) time.Duration {
+	if s, ok := config.GetString(key); ok == true {
+		d, err := time.ParseDuration(s)
+		PanicOnError(err)
+		return d
+	}
+
+	if i, ok := config.GetInt(key); ok == true {
+		return time.Duration(i) * time.Second
+	}
+	return value
 }
 
 func NewLoader(runner func(app App), priority int) *ServiceLoader {
@@ -38,8 +128,23 @@ func NewLoader(runner func(app App), priority int) *ServiceLoader {
 type ServiceLoader struct {
 	PriorityAware
 	runner func(app App)
+	closer func(app App) errors.Error
 }
 
 func (l *ServiceLoader) Load(app App) {
 	l.runner(app)
 }
+
+// WithCloser sets a function to release resources on application's shutdown
+func (l *ServiceLoader) WithCloser(closer func(app App) errors.Error) *ServiceLoader {
+	l.closer = closer
+	return l
+}
+
+func (l *ServiceLoader) Close(app App) errors.Error {
+	if l.closer == nil {
+		return nil
+	}
+
+	return l.closer(app)
+}
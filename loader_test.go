@@ -1,6 +1,11 @@
 package lapi
 
 import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/goline/errors"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -11,4 +16,30 @@ var _ = Describe("ServerLoader", func() {
 		l.WithPriority(5)
 		Expect(l.Priority()).To(Equal(5))
 	})
+
+	It("Load should configure server and return when application shuts down", func() {
+		app := NewApp()
+		app.Config().Set("server.address", "127.0.0.1:0")
+		app.Config().Set("server.read_timeout", "5s")
+		app.Config().Set("server.idle_timeout", 60)
+		app.WithServer(new(http.Server))
+		Expect(app.Shutdown(context.Background())).To(BeNil())
+
+		new(ServerLoader).Load(app)
+		Expect(app.Server().Handler).To(Equal(app))
+		Expect(app.Server().ReadTimeout).To(Equal(5 * time.Second))
+		Expect(app.Server().IdleTimeout).To(Equal(time.Minute))
+	})
+})
+
+var _ = Describe("ServiceLoader", func() {
+	It("Close should run closer", func() {
+		closed := false
+		l := NewLoader(func(app App) {}, PRIORITY_DEFAULT).WithCloser(func(app App) errors.Error {
+			closed = true
+			return nil
+		})
+		Expect(l.Close(NewApp())).To(BeNil())
+		Expect(closed).To(BeTrue())
+	})
 })
@@ -0,0 +1,388 @@
+package lapi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"reflect"
+
+	"github.com/goline/errors"
+)
+
+// Multipart reads multipart/form-data request's body
+type Multipart interface {
+	// NextPart streams the next part of body, it returns nil when there are no parts left.
+	// Content of a file part is limited by MaxFileSize
+	NextPart() (*MultipartPart, errors.Error)
+
+	// Form reads all remaining parts and caches them. Files are kept in memory up to MaxMemory,
+	// bigger ones are stored in temporary files
+	Form() (*MultipartForm, errors.Error)
+
+	// Value returns the first value of form's field
+	Value(key string) (string, bool)
+
+	// File returns the first file of form's field
+	File(key string) (*MultipartFile, bool)
+
+	// Bind sets target's fields tagged with `form:"name"`. Fields of type *MultipartFile
+	// or []*MultipartFile receive files, others are converted as ParamBinder does
+	Bind(target interface{}) errors.Error
+
+	// RemoveAll removes temporary files of form
+	RemoveAll() errors.Error
+}
+
+// MultipartLimits restricts resources which are used to read multipart body
+type MultipartLimits struct {
+	// MaxMemory is the number of bytes of values and files kept in memory,
+	// DefaultMultipartLimits.MaxMemory is used if 0
+	MaxMemory int64
+
+	// MaxDisk is the number of bytes of files stored on disk, it is unlimited if 0
+	MaxDisk int64
+
+	// MaxFileSize is the maximum size of each file, it is unlimited if 0
+	MaxFileSize int64
+
+	// TempDir is the directory of temporary files, os.TempDir() is used if empty
+	TempDir string
+}
+
+// DefaultMultipartLimits keeps 32MB in memory and does not limit disk nor files
+var DefaultMultipartLimits = MultipartLimits{MaxMemory: 32 << 20}
+
+// MultipartForm contains values and files of a multipart body
+type MultipartForm struct {
+	Value map[string][]string
+	File  map[string][]*MultipartFile
+}
+
+// MultipartFile describes an uploaded file
+type MultipartFile struct {
+	Filename string
+	Header   textproto.MIMEHeader
+	Size     int64
+
+	content []byte
+	tmpfile string
+}
+
+// Open returns file's content
+func (f *MultipartFile) Open() (multipart.File, error) {
+	if f.tmpfile != "" {
+		return os.Open(f.tmpfile)
+	}
+	return &memoryFile{bytes.NewReader(f.content)}, nil
+}
+
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (f *memoryFile) Close() error {
+	return nil
+}
+
+// MultipartPart is a streamed part, its content is limited by MaxFileSize
+type MultipartPart struct {
+	*multipart.Part
+	reader io.Reader
+}
+
+func (p *MultipartPart) Read(b []byte) (int, error) {
+	return p.reader.Read(b)
+}
+
+// NewMultipart returns an instance of Multipart reading body of request.
+// It returns ERR_MULTIPART_INVALID if request's content is not multipart/form-data
+func NewMultipart(req *http.Request, limits MultipartLimits) (Multipart, errors.Error) {
+	if req == nil || req.Body == nil {
+		return nil, errors.New(ERR_MULTIPART_INVALID, "Request has no multipart body").WithStatus(http.StatusBadRequest)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get(HEADER_CONTENT_TYPE))
+	if err != nil || mediaType != CONTENT_TYPE_MULTIPART || params["boundary"] == "" {
+		return nil, errors.New(ERR_MULTIPART_INVALID, fmt.Sprintf("Content %s is not %s", req.Header.Get(HEADER_CONTENT_TYPE), CONTENT_TYPE_MULTIPART)).
+			WithStatus(http.StatusBadRequest)
+	}
+
+	if limits.MaxMemory <= 0 {
+		limits.MaxMemory = DefaultMultipartLimits.MaxMemory
+	}
+	return &FactoryMultipart{
+		reader:    multipart.NewReader(req.Body, params["boundary"]),
+		limits:    limits,
+		validator: NewValidator(),
+	}, nil
+}
+
+type FactoryMultipart struct {
+	reader    *multipart.Reader
+	limits    MultipartLimits
+	form      *MultipartForm
+	validator Validator
+	memory    int64
+	disk      int64
+}
+
+func (m *FactoryMultipart) NextPart() (*MultipartPart, errors.Error) {
+	if m.form != nil {
+		return nil, nil
+	}
+
+	part, err := m.reader.NextPart()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if e, ok := causeOf(err); ok == true {
+		return nil, e
+	}
+	if err != nil {
+		return nil, errors.New(ERR_MULTIPART_INVALID, "Unable to read multipart body").
+			WithStatus(http.StatusBadRequest).
+			WithDebug(err.Error())
+	}
+
+	var reader io.Reader = part
+	if part.FileName() != "" && m.limits.MaxFileSize > 0 {
+		reader = &limitedReader{
+			reader:    part,
+			remaining: m.limits.MaxFileSize,
+			err: errors.New(ERR_MULTIPART_LIMIT_EXCEEDED, fmt.Sprintf("File %s exceeds %d bytes", part.FileName(), m.limits.MaxFileSize)).
+				WithStatus(http.StatusRequestEntityTooLarge),
+		}
+	}
+	return &MultipartPart{Part: part, reader: reader}, nil
+}
+
+func (m *FactoryMultipart) Form() (*MultipartForm, errors.Error) {
+	if m.form != nil {
+		return m.form, nil
+	}
+
+	form := &MultipartForm{Value: make(map[string][]string), File: make(map[string][]*MultipartFile)}
+	for {
+		part, err := m.NextPart()
+		if err != nil {
+			m.removeFiles(form)
+			return nil, err
+		}
+		if part == nil {
+			break
+		}
+
+		if part.FileName() == "" {
+			value, err := m.readValue(part)
+			if err != nil {
+				m.removeFiles(form)
+				return nil, err
+			}
+			form.Value[part.FormName()] = append(form.Value[part.FormName()], value)
+			continue
+		}
+
+		file, err := m.readFile(part)
+		if err != nil {
+			m.removeFiles(form)
+			return nil, err
+		}
+		form.File[part.FormName()] = append(form.File[part.FormName()], file)
+	}
+
+	m.form = form
+	return m.form, nil
+}
+
+func (m *FactoryMultipart) Value(key string) (string, bool) {
+	form, err := m.Form()
+	if err != nil || len(form.Value[key]) == 0 {
+		return "", false
+	}
+	return form.Value[key][0], true
+}
+
+func (m *FactoryMultipart) File(key string) (*MultipartFile, bool) {
+	form, err := m.Form()
+	if err != nil || len(form.File[key]) == 0 {
+		return nil, false
+	}
+	return form.File[key][0], true
+}
+
+func (m *FactoryMultipart) Bind(target interface{}) errors.Error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return errors.New(ERR_BIND_INVALID_TARGET, fmt.Sprintf("Binding to %T is not supported. Expects a pointer to struct", target))
+	}
+
+	form, err := m.Form()
+	if err != nil {
+		return err
+	}
+
+	fields := m.bindStruct(form, v.Elem())
+	if len(fields) > 0 {
+		return NewFieldErrors(fields)
+	}
+	return validatePart(m.validator, target, func(field reflect.StructField) bool {
+		return isParameterField(field) == false
+	})
+}
+
+func (m *FactoryMultipart) RemoveAll() errors.Error {
+	if m.form == nil {
+		return nil
+	}
+	return m.removeFiles(m.form)
+}
+
+var (
+	multipartFileType  = reflect.TypeOf((*MultipartFile)(nil))
+	multipartFilesType = reflect.TypeOf([]*MultipartFile(nil))
+)
+
+func (m *FactoryMultipart) bindStruct(form *MultipartForm, v reflect.Value) []FieldError {
+	binder := new(FactoryParamBinder)
+	fields := make([]FieldError, 0)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous == true && sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, m.bindStruct(form, v.Field(i))...)
+			continue
+		}
+
+		key, ok := sf.Tag.Lookup("form")
+		if ok == false || sf.PkgPath != "" {
+			continue
+		}
+
+		switch sf.Type {
+		case multipartFileType:
+			if files := form.File[key]; len(files) > 0 {
+				v.Field(i).Set(reflect.ValueOf(files[0]))
+			}
+			continue
+		case multipartFilesType:
+			v.Field(i).Set(reflect.ValueOf(form.File[key]))
+			continue
+		}
+
+		values, ok := form.Value[key]
+		if ok == false {
+			d, ok := sf.Tag.Lookup("default")
+			if ok == false {
+				continue
+			}
+			values = binder.split(d, sf.Type)
+		}
+
+		if err := binder.set(v.Field(i), values, sf.Tag.Get("layout")); err != nil {
+			fields = append(fields, FieldError{Field: key, In: "form", Message: err.Error()})
+		}
+	}
+	return fields
+}
+
+// readValue reads a non-file part, values are counted against MaxMemory
+func (m *FactoryMultipart) readValue(part *MultipartPart) (string, errors.Error) {
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(part, m.limits.MaxMemory-m.memory+1))
+	if err != nil {
+		return "", m.readError(err)
+	}
+
+	m.memory += n
+	if m.memory > m.limits.MaxMemory {
+		return "", errors.New(ERR_MULTIPART_LIMIT_EXCEEDED, fmt.Sprintf("Form values exceed %d bytes", m.limits.MaxMemory)).
+			WithStatus(http.StatusRequestEntityTooLarge)
+	}
+	return buf.String(), nil
+}
+
+// readFile keeps file in memory if it fits into MaxMemory, otherwise it is stored in a temporary file
+func (m *FactoryMultipart) readFile(part *MultipartPart) (*MultipartFile, errors.Error) {
+	file := &MultipartFile{Filename: part.FileName(), Header: part.Header}
+
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(part, m.limits.MaxMemory-m.memory+1))
+	if err != nil {
+		return nil, m.readError(err)
+	}
+	if m.memory+n <= m.limits.MaxMemory {
+		m.memory += n
+		file.content = buf.Bytes()
+		file.Size = n
+		return file, nil
+	}
+
+	tmp, err := ioutil.TempFile(m.limits.TempDir, "multipart-")
+	if err != nil {
+		return nil, errors.New(ERR_MULTIPART_STORAGE_FAILURE, "Unable to store uploaded file").WithDebug(err.Error())
+	}
+	defer tmp.Close()
+	file.tmpfile = tmp.Name()
+
+	var reader io.Reader = io.MultiReader(&buf, part)
+	if m.limits.MaxDisk > 0 {
+		reader = &limitedReader{
+			reader:    reader,
+			remaining: m.limits.MaxDisk - m.disk,
+			err: errors.New(ERR_MULTIPART_LIMIT_EXCEEDED, fmt.Sprintf("Uploaded files exceed %d bytes on disk", m.limits.MaxDisk)).
+				WithStatus(http.StatusRequestEntityTooLarge),
+		}
+	}
+	n, err = io.Copy(tmp, reader)
+	m.disk += n
+	file.Size = n
+	if err != nil {
+		os.Remove(file.tmpfile)
+		return nil, m.readError(err)
+	}
+	return file, nil
+}
+
+func (m *FactoryMultipart) readError(err error) errors.Error {
+	if e, ok := causeOf(err); ok == true {
+		return e
+	}
+	return errors.New(ERR_BODY_READER_FAILURE, "Unable to read multipart body").WithDebug(err.Error())
+}
+
+// causeOf finds errors.Error which is wrapped by err, such as limit of body
+func causeOf(err error) (errors.Error, bool) {
+	for err != nil {
+		if e, ok := err.(errors.Error); ok == true {
+			return e, true
+		}
+
+		u, ok := err.(interface{ Unwrap() error })
+		if ok == false {
+			break
+		}
+		err = u.Unwrap()
+	}
+	return nil, false
+}
+
+func (m *FactoryMultipart) removeFiles(form *MultipartForm) errors.Error {
+	var err errors.Error
+	for _, files := range form.File {
+		for _, file := range files {
+			if file.tmpfile == "" {
+				continue
+			}
+			if e := os.Remove(file.tmpfile); e != nil && os.IsNotExist(e) == false && err == nil {
+				err = errors.New(ERR_MULTIPART_STORAGE_FAILURE, "Unable to remove uploaded file").WithDebug(e.Error())
+			}
+		}
+	}
+	return err
+}
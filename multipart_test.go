@@ -0,0 +1,155 @@
+package lapi
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type multipartInput struct {
+	Name   string           `form:"name" validate:"required"`
+	Page   int              `form:"page" default:"1"`
+	Avatar *MultipartFile   `form:"avatar"`
+	Photos []*MultipartFile `form:"photo"`
+}
+
+func newMultipartRequest(values map[string]string, files map[string]string) *http.Request {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	for key, value := range values {
+		w.WriteField(key, value)
+	}
+	for key, content := range files {
+		f, _ := w.CreateFormFile(strings.Split(key, "#")[0], key+".txt")
+		f.Write([]byte(content))
+	}
+	w.Close()
+
+	req := httptest.NewRequest("POST", "/upload", body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func readMultipartFile(f *MultipartFile) string {
+	r, err := f.Open()
+	Expect(err).To(BeNil())
+	defer r.Close()
+	content, _ := ioutil.ReadAll(r)
+	return string(content)
+}
+
+var _ = Describe("FactoryMultipart", func() {
+	It("NewMultipart should return error code ERR_MULTIPART_INVALID", func() {
+		_, err := NewMultipart(httptest.NewRequest("POST", "/", strings.NewReader("{}")), DefaultMultipartLimits)
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_MULTIPART_INVALID))
+		Expect(err.Status()).To(Equal(http.StatusBadRequest))
+	})
+
+	It("Bind should set values and files", func() {
+		r := NewRequest(newMultipartRequest(map[string]string{"name": "foo"}, map[string]string{"avatar": "a", "photo": "p"}))
+		m, err := r.Multipart()
+		Expect(err).To(BeNil())
+
+		input := new(multipartInput)
+		Expect(m.Bind(input)).To(BeNil())
+		Expect(input.Name).To(Equal("foo"))
+		Expect(input.Page).To(Equal(1))
+		Expect(input.Avatar.Filename).To(Equal("avatar.txt"))
+		Expect(readMultipartFile(input.Avatar)).To(Equal("a"))
+		Expect(len(input.Photos)).To(Equal(1))
+
+		value, ok := m.Value("name")
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal("foo"))
+	})
+
+	It("Bind should validate fields", func() {
+		r := NewRequest(newMultipartRequest(nil, nil))
+		m, _ := r.Multipart()
+		err := m.Bind(new(multipartInput))
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_HTTP_BAD_REQUEST))
+	})
+
+	It("Form should store files exceeding memory in temporary files", func() {
+		dir, _ := ioutil.TempDir("", "lapi")
+		defer os.RemoveAll(dir)
+		r := NewRequest(newMultipartRequest(nil, map[string]string{"avatar": "0123456789"}))
+		r.WithMultipartLimits(MultipartLimits{MaxMemory: 4, TempDir: dir})
+		m, _ := r.Multipart()
+
+		f, ok := m.File("avatar")
+		Expect(ok).To(BeTrue())
+		Expect(f.Size).To(Equal(int64(10)))
+		Expect(readMultipartFile(f)).To(Equal("0123456789"))
+		entries, _ := ioutil.ReadDir(dir)
+		Expect(len(entries)).To(Equal(1))
+
+		Expect(r.(Disposable).Dispose()).To(BeNil())
+		entries, _ = ioutil.ReadDir(dir)
+		Expect(len(entries)).To(Equal(0))
+	})
+
+	It("Form should return error code ERR_MULTIPART_LIMIT_EXCEEDED", func() {
+		dir, _ := ioutil.TempDir("", "lapi")
+		defer os.RemoveAll(dir)
+		limits := []MultipartLimits{
+			{MaxMemory: 32, MaxFileSize: 4},
+			{MaxMemory: 4, MaxDisk: 4, TempDir: dir},
+		}
+		for _, limit := range limits {
+			r := NewRequest(newMultipartRequest(nil, map[string]string{"avatar": "0123456789"}))
+			m, _ := r.WithMultipartLimits(limit).Multipart()
+			_, err := m.Form()
+			Expect(err).NotTo(BeNil())
+			Expect(err.Code()).To(Equal(ERR_MULTIPART_LIMIT_EXCEEDED))
+			Expect(err.Status()).To(Equal(http.StatusRequestEntityTooLarge))
+		}
+		entries, _ := ioutil.ReadDir(dir)
+		Expect(len(entries)).To(Equal(0))
+	})
+
+	It("Form should return error code ERR_HTTP_REQUEST_ENTITY_TOO_LARGE when body exceeds its limit", func() {
+		for _, size := range []int64{16, 256} {
+			r := NewRequest(newMultipartRequest(nil, map[string]string{"avatar": strings.Repeat("0", 1024)}))
+			r.Body().WithMaxSize(size)
+			m, _ := r.Multipart()
+			_, err := m.Form()
+			Expect(err).NotTo(BeNil())
+			Expect(err.Code()).To(Equal(ERR_HTTP_REQUEST_ENTITY_TOO_LARGE))
+			Expect(err.Status()).To(Equal(http.StatusRequestEntityTooLarge))
+		}
+	})
+
+	It("Form should keep values and files in memory when MaxMemory is not set", func() {
+		r := NewRequest(newMultipartRequest(map[string]string{"name": "john"}, map[string]string{"avatar": "0123456789"}))
+		m, _ := r.WithMultipartLimits(MultipartLimits{MaxFileSize: 1024}).Multipart()
+		form, err := m.Form()
+		Expect(err).To(BeNil())
+		Expect(form.Value["name"]).To(Equal([]string{"john"}))
+		Expect(form.File["avatar"][0].tmpfile).To(BeEmpty())
+		Expect(readMultipartFile(form.File["avatar"][0])).To(Equal("0123456789"))
+	})
+
+	It("NextPart should stream parts", func() {
+		r := NewRequest(newMultipartRequest(nil, map[string]string{"avatar": "0123456789"}))
+		m, _ := r.WithMultipartLimits(MultipartLimits{MaxFileSize: 4}).Multipart()
+		part, err := m.NextPart()
+		Expect(err).To(BeNil())
+		Expect(part.FormName()).To(Equal("avatar"))
+		_, e := ioutil.ReadAll(part)
+		Expect(e).NotTo(BeNil())
+
+		part, err = m.NextPart()
+		Expect(err).To(BeNil())
+		Expect(part).To(BeNil())
+	})
+})
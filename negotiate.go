@@ -0,0 +1,142 @@
+package lapi
+
+import (
+	"strconv"
+	"strings"
+)
+
+// mediaRange is an item of Accept header, such as text/*;q=0.5
+type mediaRange struct {
+	kind    string
+	subtype string
+	quality float64
+}
+
+// matches returns specificity of matching contentType, it is 0 if range does not match
+func (m *mediaRange) matches(contentType string) int {
+	kind, subtype := splitContentType(contentType)
+	switch {
+	case m.kind == "*" && m.subtype == "*":
+		return 1
+	case m.kind == kind && m.subtype == "*":
+		return 2
+	case m.kind == kind && m.subtype == subtype:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// parseAccept returns media ranges of Accept header, invalid items are ignored
+func parseAccept(accept string) []*mediaRange {
+	ranges := make([]*mediaRange, 0)
+	for _, item := range strings.Split(accept, ",") {
+		params := strings.Split(item, ";")
+		kind, subtype := splitContentType(params[0])
+		if kind == "" || subtype == "" {
+			continue
+		}
+
+		m := &mediaRange{kind: kind, subtype: subtype, quality: 1}
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") == false {
+				continue
+			}
+			if q, err := strconv.ParseFloat(param[2:], 64); err == nil && q >= 0 && q <= 1 {
+				m.quality = q
+			}
+		}
+		ranges = append(ranges, m)
+	}
+	return ranges
+}
+
+func splitContentType(contentType string) (string, string) {
+	parts := strings.SplitN(strings.ToLower(strings.TrimSpace(contentType)), "/", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
+
+// negotiate picks the offer which client accepts with the highest quality.
+// Quality of an offer comes from the most specific media range matching it,
+// ties are resolved by order of offers. It returns false if no offers are acceptable
+func negotiate(accept string, offers []string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		if len(offers) == 0 {
+			return "", false
+		}
+		return offers[0], true
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQuality := "", 0.0
+	for _, offer := range offers {
+		quality, specificity := 0.0, 0
+		for _, m := range ranges {
+			if s := m.matches(offer); s > specificity {
+				quality, specificity = m.quality, s
+			}
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+	return best, bestQuality > 0
+}
+
+// negotiateEncoding picks gzip or deflate from Accept-Encoding header, gzip is preferred on ties.
+// It returns false if client does not accept any of them
+func negotiateEncoding(accept string) (string, bool) {
+	qualities := make(map[string]float64)
+	for _, item := range strings.Split(accept, ",") {
+		params := strings.Split(item, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+		if coding == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") == false {
+				continue
+			}
+			if q, err := strconv.ParseFloat(param[2:], 64); err == nil && q >= 0 && q <= 1 {
+				quality = q
+			}
+		}
+		qualities[coding] = quality
+	}
+
+	best, bestQuality := "", 0.0
+	for _, coding := range []string{ENCODING_GZIP, ENCODING_DEFLATE} {
+		quality, ok := qualities[coding]
+		if ok == false {
+			quality = qualities["*"]
+		}
+		if quality > bestQuality {
+			best, bestQuality = coding, quality
+		}
+	}
+	return best, bestQuality > 0
+}
+
+// addVary appends value to Vary header unless it is listed already
+func addVary(header Header, value string) {
+	vary, ok := header.Get(HEADER_VARY)
+	if ok == false || vary == "" {
+		header.Set(HEADER_VARY, value)
+		return
+	}
+
+	for _, item := range strings.Split(vary, ",") {
+		if strings.EqualFold(strings.TrimSpace(item), value) {
+			return
+		}
+	}
+	header.Set(HEADER_VARY, vary+", "+value)
+}
@@ -0,0 +1,97 @@
+package lapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("negotiate", func() {
+	offers := []string{CONTENT_TYPE_JSON, CONTENT_TYPE_XML, CONTENT_TYPE_TEXT}
+
+	It("should pick offer of the highest quality", func() {
+		contentType, ok := negotiate("application/json;q=0.5, application/xml", offers)
+		Expect(ok).To(BeTrue())
+		Expect(contentType).To(Equal(CONTENT_TYPE_XML))
+	})
+
+	It("should prefer the most specific media range", func() {
+		contentType, ok := negotiate("text/plain;q=0.1, text/*;q=0.8, */*;q=0.5", offers)
+		Expect(ok).To(BeTrue())
+		Expect(contentType).To(Equal(CONTENT_TYPE_JSON))
+
+		contentType, ok = negotiate("text/*, */*;q=0.5", offers)
+		Expect(ok).To(BeTrue())
+		Expect(contentType).To(Equal(CONTENT_TYPE_TEXT))
+	})
+
+	It("should resolve ties by order of offers", func() {
+		contentType, ok := negotiate("*/*", offers)
+		Expect(ok).To(BeTrue())
+		Expect(contentType).To(Equal(CONTENT_TYPE_JSON))
+	})
+
+	It("should return false if nothing is acceptable", func() {
+		_, ok := negotiate("image/png, application/json;q=0", offers)
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("NegotiationHook", func() {
+	It("should set response's content-type from Accept header", func() {
+		app := NewApp()
+		app.Router().Get("/foo", &appHandler{}).
+			WithHook(new(SystemHook)).
+			WithHook(new(MultiParserHook)).
+			WithHook(new(NegotiationHook))
+		app.Run()
+
+		req := httptest.NewRequest("GET", "/foo", nil)
+		req.Header.Set("Accept", "application/xml;q=0.9, text/plain")
+		rw := httptest.NewRecorder()
+		app.ServeHTTP(rw, req)
+		res := rw.Result()
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		Expect(res.Header.Get("Content-Type")).To(Equal("text/plain; charset=utf-8"))
+		Expect(res.Header.Get("Vary")).To(Equal("Accept"))
+		body, _ := ioutil.ReadAll(res.Body)
+		Expect(string(body)).To(Equal("map[foo:bar]"))
+	})
+
+	It("should return response with error code ERR_HTTP_NOT_ACCEPTABLE", func() {
+		app := NewApp()
+		app.Router().Get("/foo", &appHandler{}).
+			WithHook(new(SystemHook)).
+			WithHook(new(ParserHook)).
+			WithHook(new(NegotiationHook))
+		app.Run()
+
+		req := httptest.NewRequest("GET", "/foo", nil)
+		req.Header.Set("Accept", "application/xml")
+		rw := httptest.NewRecorder()
+		app.ServeHTTP(rw, req)
+		res := rw.Result()
+		Expect(res.StatusCode).To(Equal(http.StatusNotAcceptable))
+		body, _ := ioutil.ReadAll(res.Body)
+		Expect(string(body)).To(ContainSubstring(ERR_HTTP_NOT_ACCEPTABLE))
+	})
+
+	It("should skip negotiation when no parsers are registered", func() {
+		req := httptest.NewRequest("GET", "/foo", nil)
+		req.Header.Set("Accept", "application/xml")
+		c := NewConnection(NewRequest(req), NewJsonResponse(httptest.NewRecorder()))
+		Expect(new(NegotiationHook).SetUp(c)).To(BeNil())
+		Expect(c.Response().Body().ContentType()).To(Equal(CONTENT_TYPE_JSON))
+	})
+	It("should not pick content-type of wildcard parsers", func() {
+		req := httptest.NewRequest("GET", "/foo", nil)
+		req.Header.Set("Accept", "text/*, application/json;q=0.5")
+		c := NewConnection(NewRequest(req), NewJsonResponse(httptest.NewRecorder()))
+		c.Response().Body().WithParser(new(JsonParser)).WithParser(&parserWildcard{})
+		Expect(new(NegotiationHook).SetUp(c)).To(BeNil())
+		Expect(c.Response().Body().ContentType()).To(Equal(CONTENT_TYPE_JSON))
+	})
+})
@@ -0,0 +1,444 @@
+package lapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goline/errors"
+)
+
+const OPENAPI_VERSION = "3.0.3"
+
+// OpenApi is an OpenAPI 3 document
+type OpenApi struct {
+	OpenApi    string                     `json:"openapi"`
+	Info       OpenApiInfo                `json:"info"`
+	Tags       []OpenApiTag               `json:"tags,omitempty"`
+	Paths      map[string]OpenApiPathItem `json:"paths"`
+	Components OpenApiComponents          `json:"components"`
+}
+
+type OpenApiInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type OpenApiTag struct {
+	Name string `json:"name"`
+}
+
+// OpenApiPathItem maps lower-cased HTTP method to operation
+type OpenApiPathItem map[string]*OpenApiOperation
+
+type OpenApiOperation struct {
+	OperationId string                     `json:"operationId,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []*OpenApiParameter        `json:"parameters,omitempty"`
+	RequestBody *OpenApiRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenApiContent `json:"responses"`
+}
+
+type OpenApiParameter struct {
+	Name     string      `json:"name"`
+	In       string      `json:"in"`
+	Required bool        `json:"required,omitempty"`
+	Schema   *JsonSchema `json:"schema"`
+}
+
+type OpenApiRequestBody struct {
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*OpenApiMediaType `json:"content"`
+}
+
+type OpenApiContent struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenApiMediaType `json:"content,omitempty"`
+}
+
+type OpenApiMediaType struct {
+	Schema *JsonSchema `json:"schema"`
+}
+
+type OpenApiComponents struct {
+	Schemas map[string]*JsonSchema `json:"schemas,omitempty"`
+}
+
+// JsonSchema describes a value in form of JSON Schema used by OpenAPI 3
+type JsonSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Nullable             bool                   `json:"nullable,omitempty"`
+	Items                *JsonSchema            `json:"items,omitempty"`
+	Properties           map[string]*JsonSchema `json:"properties,omitempty"`
+	AdditionalProperties *JsonSchema            `json:"additionalProperties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+}
+
+// Json encodes document as JSON
+func (d *OpenApi) Json() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// Yaml encodes document as YAML
+func (d *OpenApi) Yaml() ([]byte, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	writeYaml(buf, v, 0)
+	return buf.Bytes(), nil
+}
+
+// NewOpenApiGenerator returns a generator of OpenAPI 3 document
+func NewOpenApiGenerator(title string, version string) *OpenApiGenerator {
+	return &OpenApiGenerator{
+		Info: OpenApiInfo{Title: title, Version: version},
+	}
+}
+
+// OpenApiGenerator walks router's routes and reflects input, output of IOHandler to schemas
+type OpenApiGenerator struct {
+	Info OpenApiInfo
+
+	schemas map[string]*JsonSchema
+	names   map[reflect.Type]string
+}
+
+// Generate builds document from all routes of router
+func (g *OpenApiGenerator) Generate(router Router) *OpenApi {
+	g.schemas = make(map[string]*JsonSchema)
+	g.names = make(map[reflect.Type]string)
+	errorSchema := g.schemaOf(reflect.TypeOf(ErrorResponse{}))
+
+	doc := &OpenApi{
+		OpenApi: OPENAPI_VERSION,
+		Info:    g.Info,
+		Paths:   make(map[string]OpenApiPathItem),
+	}
+	tags := make(map[string]bool)
+	for _, route := range router.Routes() {
+		if route.Method() == "" {
+			continue
+		}
+
+		path, parameters := g.pathOf(route)
+		operation := &OpenApiOperation{
+			OperationId: route.Name(),
+			Tags:        route.Tags(),
+			Parameters:  parameters,
+			Responses: map[string]*OpenApiContent{
+				"default": {
+					Description: "Error",
+					Content:     map[string]*OpenApiMediaType{CONTENT_TYPE_JSON: {errorSchema}},
+				},
+			},
+		}
+		for _, tag := range route.Tags() {
+			tags[tag] = true
+		}
+
+		input, output := g.ioOf(route)
+		if input != nil && g.hasRequestBody(route.Method()) {
+			operation.RequestBody = &OpenApiRequestBody{
+				Required: true,
+				Content:  map[string]*OpenApiMediaType{CONTENT_TYPE_JSON: {g.schemaOf(input)}},
+			}
+		}
+		success := &OpenApiContent{Description: http.StatusText(http.StatusOK)}
+		if output != nil {
+			success.Content = map[string]*OpenApiMediaType{CONTENT_TYPE_JSON: {g.schemaOf(output)}}
+		}
+		operation.Responses[strconv.Itoa(http.StatusOK)] = success
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(OpenApiPathItem)
+		}
+		doc.Paths[path][strings.ToLower(route.Method())] = operation
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, OpenApiTag{tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool {
+		return doc.Tags[i].Name < doc.Tags[j].Name
+	})
+	doc.Components.Schemas = g.schemas
+	return doc
+}
+
+func (g *OpenApiGenerator) ioOf(route Route) (input reflect.Type, output reflect.Type) {
+	if r, ok := route.(*FactoryRoute); ok == true {
+		return r.requestInput, r.responseOutput
+	}
+
+	if h, ok := route.Handler().(IOHandler); ok == true {
+		in, out := h.IO()
+		return reflect.TypeOf(in), reflect.TypeOf(out)
+	}
+	return nil, nil
+}
+
+func (g *OpenApiGenerator) hasRequestBody(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return true
+	default:
+		return false
+	}
+}
+
+// pathOf converts placeholders <name:regex> to OpenAPI path templates {name}
+func (g *OpenApiGenerator) pathOf(route Route) (string, []*OpenApiParameter) {
+	uri := strings.TrimSuffix(strings.TrimPrefix(route.Uri(), "^"), "$")
+	parameters := make([]*OpenApiParameter, 0)
+	path := routeKeyRegexp.ReplaceAllStringFunc(uri, func(s string) string {
+		m := routeKeyRegexp.FindStringSubmatch(s)
+		parameters = append(parameters, &OpenApiParameter{
+			Name:     m[2],
+			In:       "path",
+			Required: true,
+			Schema:   g.paramSchemaOf(route, m[3]),
+		})
+		return "{" + m[2] + "}"
+	})
+	return path, parameters
+}
+
+// paramSchemaOf describes placeholder's pattern, built-in types have their own formats
+func (g *OpenApiGenerator) paramSchemaOf(route Route, pattern string) *JsonSchema {
+	r, ok := route.(*FactoryRoute)
+	if ok == false {
+		return &JsonSchema{Type: "string", Pattern: "^(?:" + pattern + ")$"}
+	}
+
+	p, t := r.paramPattern(pattern)
+	switch t {
+	case paramTypes["int"]:
+		return &JsonSchema{Type: "integer", Format: "int64"}
+	case paramTypes["uuid"]:
+		return &JsonSchema{Type: "string", Format: "uuid"}
+	case paramTypes["date"]:
+		return &JsonSchema{Type: "string", Format: "date"}
+	default:
+		return &JsonSchema{Type: "string", Pattern: "^(?:" + p + ")$"}
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (g *OpenApiGenerator) schemaOf(t reflect.Type) *JsonSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &JsonSchema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return &JsonSchema{Type: "string", Format: "byte"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &JsonSchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &JsonSchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &JsonSchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &JsonSchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &JsonSchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &JsonSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &JsonSchema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &JsonSchema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return g.refOf(t)
+	default:
+		return &JsonSchema{}
+	}
+}
+
+// refOf registers named struct as component and returns reference to it
+func (g *OpenApiGenerator) refOf(t reflect.Type) *JsonSchema {
+	if t.Name() == "" {
+		return g.structOf(t)
+	}
+
+	name, ok := g.names[t]
+	if ok == false {
+		name = t.Name()
+		if _, exists := g.schemas[name]; exists == true {
+			name = strings.Replace(t.String(), ".", "_", -1)
+		}
+		g.names[t] = name
+		g.schemas[name] = &JsonSchema{}
+		*g.schemas[name] = *g.structOf(t)
+	}
+	return &JsonSchema{Ref: "#/components/schemas/" + name}
+}
+
+func (g *OpenApiGenerator) structOf(t reflect.Type) *JsonSchema {
+	s := &JsonSchema{Type: "object", Properties: make(map[string]*JsonSchema)}
+	g.fieldsOf(t, s)
+	return s
+}
+
+func (g *OpenApiGenerator) fieldsOf(t reflect.Type, s *JsonSchema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options := tag, ""
+		if i := strings.Index(tag, ","); i >= 0 {
+			name, options = tag[:i], tag[i+1:]
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			g.fieldsOf(ft, s)
+			continue
+		}
+
+		if f.PkgPath != "" {
+			// unexported field
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		property := g.schemaOf(f.Type)
+		if description, ok := f.Tag.Lookup("description"); ok == true && property.Ref == "" {
+			// siblings of $ref are ignored by OpenAPI 3.0
+			property.Description = description
+		}
+		if f.Type.Kind() == reflect.Ptr && property.Ref == "" {
+			property.Nullable = true
+		}
+		s.Properties[name] = property
+
+		if strings.Contains(options, "omitempty") == false && f.Type.Kind() != reflect.Ptr {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+var yamlPlainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+func writeYaml(buf *bytes.Buffer, v interface{}, indent int) {
+	pad := strings.Repeat(" ", indent)
+	switch value := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if yamlPlainKey.MatchString(key) == true {
+				buf.WriteString(pad + key + ":")
+			} else {
+				buf.WriteString(pad + strconv.Quote(key) + ":")
+			}
+			writeYamlValue(buf, value[key], indent)
+		}
+	case []interface{}:
+		for _, item := range value {
+			buf.WriteString(pad + "-")
+			writeYamlValue(buf, item, indent)
+		}
+	}
+}
+
+func writeYamlValue(buf *bytes.Buffer, v interface{}, indent int) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if len(value) == 0 {
+			buf.WriteString(" {}\n")
+			return
+		}
+		buf.WriteString("\n")
+		writeYaml(buf, value, indent+2)
+	case []interface{}:
+		if len(value) == 0 {
+			buf.WriteString(" []\n")
+			return
+		}
+		buf.WriteString("\n")
+		writeYaml(buf, value, indent+2)
+	case string:
+		buf.WriteString(" " + strconv.Quote(value) + "\n")
+	case nil:
+		buf.WriteString(" null\n")
+	default:
+		buf.WriteString(fmt.Sprintf(" %v\n", value))
+	}
+}
+
+// NewOpenApiHandler returns a handler which serves generated document of router.
+// Document is generated on first request, then it is cached
+func NewOpenApiHandler(router Router, generator *OpenApiGenerator, yaml bool) Handler {
+	return &OpenApiHandler{router: router, generator: generator, yaml: yaml}
+}
+
+type OpenApiHandler struct {
+	router    Router
+	generator *OpenApiGenerator
+	yaml      bool
+	once      sync.Once
+	content   []byte
+	err       error
+}
+
+func (h *OpenApiHandler) Handle(c Connection) (interface{}, errors.Error) {
+	h.once.Do(func() {
+		doc := h.generator.Generate(h.router)
+		if h.yaml == true {
+			h.content, h.err = doc.Yaml()
+		} else {
+			h.content, h.err = doc.Json()
+		}
+	})
+	if h.err != nil {
+		return nil, errors.New(ERR_PARSE_ENCODE_FAILURE, "Unable to encode OpenAPI document").WithDebug(h.err.Error())
+	}
+
+	if h.yaml == true {
+		c.Response().Body().WithContentType(CONTENT_TYPE_YAML)
+	} else {
+		c.Response().Body().WithContentType(CONTENT_TYPE_JSON)
+	}
+	return nil, c.Response().Body().Write(h.content)
+}
@@ -0,0 +1,99 @@
+package lapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/goline/errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type openApiInput struct {
+	Name     string    `json:"name" description:"User's name"`
+	Age      int       `json:"age,omitempty"`
+	Birthday time.Time `json:"birthday"`
+	Secret   string    `json:"-"`
+}
+
+type openApiOutput struct {
+	Id    int64         `json:"id"`
+	Input *openApiInput `json:"input"`
+	Tags  []string      `json:"tags"`
+}
+
+type openApiHandler struct{}
+
+func (h *openApiHandler) Handle(c Connection) (interface{}, errors.Error) { return nil, nil }
+func (h *openApiHandler) IO() (interface{}, interface{}) {
+	return new(openApiInput), new(openApiOutput)
+}
+
+var _ = Describe("OpenApiGenerator", func() {
+	It("Generate should describe routes", func() {
+		r := NewRouter()
+		r.Post("/users/<id:\\d+>", new(openApiHandler)).WithName("update_user").WithTag("users")
+		r.Get("/users", nil)
+		doc := NewOpenApiGenerator("API", "1.0").Generate(r)
+
+		Expect(doc.OpenApi).To(Equal(OPENAPI_VERSION))
+		Expect(doc.Tags).To(Equal([]OpenApiTag{{"users"}}))
+		Expect(len(doc.Paths)).To(Equal(2))
+
+		operation := doc.Paths["/users/{id}"]["post"]
+		Expect(operation.OperationId).To(Equal("update_user"))
+		Expect(operation.Parameters[0].Name).To(Equal("id"))
+		Expect(operation.Parameters[0].In).To(Equal("path"))
+		Expect(operation.RequestBody.Content[CONTENT_TYPE_JSON].Schema.Ref).To(Equal("#/components/schemas/openApiInput"))
+		Expect(operation.Responses["200"].Content[CONTENT_TYPE_JSON].Schema.Ref).To(Equal("#/components/schemas/openApiOutput"))
+		Expect(operation.Responses["default"].Content[CONTENT_TYPE_JSON].Schema.Ref).To(Equal("#/components/schemas/ErrorResponse"))
+	})
+
+	It("Generate should reflect struct tags", func() {
+		r := NewRouter()
+		r.Post("/users", new(openApiHandler))
+		doc := NewOpenApiGenerator("API", "1.0").Generate(r)
+
+		input := doc.Components.Schemas["openApiInput"]
+		Expect(len(input.Properties)).To(Equal(3))
+		Expect(input.Properties["name"].Description).To(Equal("User's name"))
+		Expect(input.Properties["age"].Type).To(Equal("integer"))
+		Expect(input.Properties["birthday"].Format).To(Equal("date-time"))
+		Expect(input.Required).To(Equal([]string{"name", "birthday"}))
+
+		output := doc.Components.Schemas["openApiOutput"]
+		Expect(output.Properties["tags"].Items.Type).To(Equal("string"))
+		Expect(output.Required).To(Equal([]string{"id", "tags"}))
+
+		errorResponse := doc.Components.Schemas["ErrorResponse"]
+		Expect(errorResponse.Required).To(Equal([]string{"code", "message"}))
+	})
+
+	It("Yaml should encode document", func() {
+		r := NewRouter()
+		r.Get("/users/<id:\\d+>", nil)
+		content, err := NewOpenApiGenerator("API", "1.0").Generate(r).Yaml()
+		Expect(err).To(BeNil())
+		Expect(strings.Contains(string(content), "openapi: \"3.0.3\"\n")).To(BeTrue())
+		Expect(strings.Contains(string(content), "  \"/users/{id}\":\n    get:\n")).To(BeTrue())
+	})
+})
+
+var _ = Describe("OpenApiHandler", func() {
+	It("Handle should write document", func() {
+		r := NewRouter()
+		r.Get("/users", nil)
+		h := NewOpenApiHandler(r, NewOpenApiGenerator("API", "1.0"), false)
+		c := NewConnection(nil, NewResponse(nil))
+		_, err := h.Handle(c)
+		Expect(err).To(BeNil())
+		Expect(c.Response().Body().ContentType()).To(Equal(CONTENT_TYPE_JSON))
+		Expect(c.Response().Status()).To(Equal(http.StatusOK))
+
+		doc := new(OpenApi)
+		Expect(json.Unmarshal(c.Response().Body().(*FactoryBody).contentBytes, doc)).To(BeNil())
+		Expect(doc.Info.Title).To(Equal("API"))
+	})
+})
@@ -1,7 +1,16 @@
 package lapi
 
 import (
+	"encoding"
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
 )
 
 type Parser interface {
@@ -15,32 +24,80 @@ type Parser interface {
 	Encode(v interface{}) ([]byte, error)
 }
 
+// StreamParser decodes straight from reader, without reading whole content into memory
+type StreamParser interface {
+	// DecodeStream decodes content of reader into a specific value
+	DecodeStream(reader io.Reader, v interface{}) error
+}
+
 type ParserManager interface {
-	// Parser returns an appropriate parser
+	// Parser returns an appropriate parser. Parameters of contentType are ignored, then parser is looked up by
+	// exact content-type, structured syntax suffix (application/vnd.api+json uses application/json),
+	// type wildcard (application/*) and finally default parser
 	Parser(contentType string) (Parser, bool)
 
+	// WithDefaultParser sets parser which is used when no parsers match content-type
+	WithDefaultParser(parser Parser) ParserManager
+
 	// WithParser registers a parser
 	WithParser(parser Parser) ParserManager
+
+	// ContentTypes returns content-types of registered parsers in registration order
+	ContentTypes() []string
 }
 
 func NewParserManager() ParserManager {
-	return &FactoryParserManager{make(map[string]Parser)}
+	return &FactoryParserManager{parsers: make(map[string]Parser)}
 }
 
 type FactoryParserManager struct {
-	parsers map[string]Parser
+	parsers       map[string]Parser
+	types         []string
+	defaultParser Parser
 }
 
 func (pm *FactoryParserManager) Parser(contentType string) (Parser, bool) {
-	parser, ok := pm.parsers[contentType]
-	return parser, ok
+	if parser, ok := pm.parsers[contentType]; ok == true {
+		return parser, true
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if parser, ok := pm.parsers[mediaType]; ok == true {
+			return parser, true
+		}
+
+		kind, subtype := splitContentType(mediaType)
+		if i := strings.LastIndex(subtype, "+"); i >= 0 {
+			if parser, ok := pm.parsers["application/"+subtype[i+1:]]; ok == true {
+				return parser, true
+			}
+		}
+
+		if parser, ok := pm.parsers[kind+"/*"]; ok == true {
+			return parser, true
+		}
+	}
+
+	return pm.defaultParser, pm.defaultParser != nil
+}
+
+func (pm *FactoryParserManager) WithDefaultParser(parser Parser) ParserManager {
+	pm.defaultParser = parser
+	return pm
 }
 
 func (pm *FactoryParserManager) WithParser(parser Parser) ParserManager {
+	if _, ok := pm.parsers[parser.ContentType()]; ok == false {
+		pm.types = append(pm.types, parser.ContentType())
+	}
 	pm.parsers[parser.ContentType()] = parser
 	return pm
 }
 
+func (pm *FactoryParserManager) ContentTypes() []string {
+	return pm.types
+}
+
 type JsonParser struct{}
 
 func (p *JsonParser) ContentType() string {
@@ -51,6 +108,248 @@ func (p *JsonParser) Decode(data []byte, v interface{}) error {
 	return json.Unmarshal(data, v)
 }
 
+// DecodeStream decodes a single JSON value, trailing data other than whitespace is rejected as json.Unmarshal does
+func (p *JsonParser) DecodeStream(reader io.Reader, v interface{}) error {
+	decoder := json.NewDecoder(reader)
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	_, err := decoder.Token()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("invalid data after top-level value")
+}
+
 func (p *JsonParser) Encode(v interface{}) ([]byte, error) {
 	return json.Marshal(v)
 }
+
+type XmlParser struct{}
+
+func (p *XmlParser) ContentType() string {
+	return CONTENT_TYPE_XML
+}
+
+func (p *XmlParser) Decode(data []byte, v interface{}) error {
+	return xml.Unmarshal(data, v)
+}
+
+func (p *XmlParser) DecodeStream(reader io.Reader, v interface{}) error {
+	decoder := xml.NewDecoder(reader)
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	// only whitespaces, comments and processing instructions may follow root element
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.Comment, xml.ProcInst:
+		case xml.CharData:
+			if strings.TrimSpace(string(t)) != "" {
+				return fmt.Errorf("invalid data after root element")
+			}
+		default:
+			return fmt.Errorf("invalid data after root element")
+		}
+	}
+}
+
+func (p *XmlParser) Encode(v interface{}) ([]byte, error) {
+	return xml.Marshal(v)
+}
+
+// FormParser handles application/x-www-form-urlencoded content.
+// Structs are decoded through `form` tags, such as `form:"name"`, which accept
+// `default` and `layout` tags as ParamBinder does. *url.Values and *map[string][]string are supported as well
+type FormParser struct{}
+
+func (p *FormParser) ContentType() string {
+	return CONTENT_TYPE_FORM
+}
+
+func (p *FormParser) Decode(data []byte, v interface{}) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+
+	switch t := v.(type) {
+	case *url.Values:
+		*t = values
+		return nil
+	case *map[string][]string:
+		*t = values
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("form could not be decoded into %T", v)
+	}
+	return p.decodeStruct(values, rv.Elem())
+}
+
+func (p *FormParser) decodeStruct(values url.Values, v reflect.Value) error {
+	binder := new(FactoryParamBinder)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous == true && sf.Type.Kind() == reflect.Struct {
+			if err := p.decodeStruct(values, v.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key, ok := sf.Tag.Lookup("form")
+		if ok == false || sf.PkgPath != "" {
+			continue
+		}
+
+		items, ok := values[key]
+		if ok == false {
+			d, ok := sf.Tag.Lookup("default")
+			if ok == false {
+				continue
+			}
+			items = binder.split(d, sf.Type)
+		}
+
+		if err := binder.set(v.Field(i), items, sf.Tag.Get("layout")); err != nil {
+			return fmt.Errorf("form field %q %s", key, err.Error())
+		}
+	}
+	return nil
+}
+
+func (p *FormParser) Encode(v interface{}) ([]byte, error) {
+	switch t := v.(type) {
+	case url.Values:
+		return []byte(t.Encode()), nil
+	case map[string][]string:
+		return []byte(url.Values(t).Encode()), nil
+	case map[string]string:
+		values := url.Values{}
+		for key, value := range t {
+			values.Set(key, value)
+		}
+		return []byte(values.Encode()), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%T could not be encoded as form", v)
+	}
+
+	values := url.Values{}
+	p.encodeStruct(values, rv)
+	return []byte(values.Encode()), nil
+}
+
+func (p *FormParser) encodeStruct(values url.Values, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous == true && sf.Type.Kind() == reflect.Struct {
+			p.encodeStruct(values, v.Field(i))
+			continue
+		}
+
+		key, ok := sf.Tag.Lookup("form")
+		if ok == false || sf.PkgPath != "" {
+			continue
+		}
+
+		f := v.Field(i)
+		if f.Kind() == reflect.Ptr && f.IsNil() {
+			continue
+		}
+		if f.Kind() == reflect.Slice && f.Type().Elem().Kind() != reflect.Uint8 {
+			for j := 0; j < f.Len(); j++ {
+				values.Add(key, p.format(f.Index(j), sf.Tag.Get("layout")))
+			}
+			continue
+		}
+		values.Set(key, p.format(f, sf.Tag.Get("layout")))
+	}
+}
+
+func (p *FormParser) format(v reflect.Value, layout string) string {
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	switch t := v.Interface().(type) {
+	case time.Time:
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return t.Format(layout)
+	case []byte:
+		return string(t)
+	case encoding.TextMarshaler:
+		text, err := t.MarshalText()
+		if err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// TextParser handles text/plain content. It decodes into *string, *[]byte
+// or encoding.TextUnmarshaler, and encodes any value in its text form
+type TextParser struct{}
+
+func (p *TextParser) ContentType() string {
+	return CONTENT_TYPE_TEXT
+}
+
+func (p *TextParser) Decode(data []byte, v interface{}) error {
+	switch t := v.(type) {
+	case *string:
+		*t = string(data)
+	case *[]byte:
+		*t = data
+	case encoding.TextUnmarshaler:
+		return t.UnmarshalText(data)
+	default:
+		return fmt.Errorf("text could not be decoded into %T", v)
+	}
+	return nil
+}
+
+func (p *TextParser) Encode(v interface{}) ([]byte, error) {
+	switch t := v.(type) {
+	case string:
+		return []byte(t), nil
+	case []byte:
+		return t, nil
+	case encoding.TextMarshaler:
+		return t.MarshalText()
+	case error:
+		return []byte(t.Error()), nil
+	default:
+		return []byte(fmt.Sprint(v)), nil
+	}
+}
+
+// parsers returns an instance of every built-in parser
+func parsers() []Parser {
+	return []Parser{new(JsonParser), new(XmlParser), new(FormParser), new(TextParser)}
+}
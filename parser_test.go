@@ -0,0 +1,135 @@
+package lapi
+
+import (
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type parserXmlInput struct {
+	Name string `xml:"name"`
+}
+
+type parserFormInput struct {
+	Name  string    `form:"name"`
+	Tags  []string  `form:"tag"`
+	Age   *int      `form:"age"`
+	Page  int       `form:"page" default:"1"`
+	Since time.Time `form:"since" layout:"2006-01-02"`
+}
+
+var _ = Describe("XmlParser", func() {
+	It("should encode and decode XML", func() {
+		p := new(XmlParser)
+		Expect(p.ContentType()).To(Equal(CONTENT_TYPE_XML))
+		data, err := p.Encode(&parserXmlInput{"foo"})
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("<parserXmlInput><name>foo</name></parserXmlInput>"))
+
+		input := new(parserXmlInput)
+		Expect(p.Decode(data, input)).To(BeNil())
+		Expect(input.Name).To(Equal("foo"))
+	})
+})
+
+var _ = Describe("FormParser", func() {
+	It("Decode should fill struct via form tags", func() {
+		input := new(parserFormInput)
+		err := new(FormParser).Decode([]byte("name=foo&tag=a&tag=b&age=20&since=2020-01-02"), input)
+		Expect(err).To(BeNil())
+		Expect(input.Name).To(Equal("foo"))
+		Expect(input.Tags).To(Equal([]string{"a", "b"}))
+		Expect(*input.Age).To(Equal(20))
+		Expect(input.Page).To(Equal(1))
+		Expect(input.Since).To(Equal(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("Decode should fill url.Values", func() {
+		values := url.Values{}
+		Expect(new(FormParser).Decode([]byte("a=1&a=2"), &values)).To(BeNil())
+		Expect(values["a"]).To(Equal([]string{"1", "2"}))
+	})
+
+	It("Decode should return error for invalid field", func() {
+		Expect(new(FormParser).Decode([]byte("age=x"), new(parserFormInput))).NotTo(BeNil())
+		Expect(new(FormParser).Decode([]byte("a=1"), new(string))).NotTo(BeNil())
+	})
+
+	It("Encode should write struct via form tags", func() {
+		age := 20
+		data, err := new(FormParser).Encode(&parserFormInput{Name: "foo", Tags: []string{"a", "b"}, Age: &age, Since: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)})
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("age=20&name=foo&page=0&since=2020-01-02&tag=a&tag=b"))
+
+		data, err = new(FormParser).Encode(map[string]string{"b": "2", "a": "1"})
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("a=1&b=2"))
+	})
+})
+
+var _ = Describe("TextParser", func() {
+	It("should encode and decode plain text", func() {
+		p := new(TextParser)
+		var s string
+		Expect(p.Decode([]byte("foo"), &s)).To(BeNil())
+		Expect(s).To(Equal("foo"))
+		Expect(p.Decode([]byte("foo"), new(int))).NotTo(BeNil())
+
+		data, err := p.Encode(15)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("15"))
+	})
+})
+
+var _ = Describe("MultiParserHook", func() {
+	It("SetUp should register all parsers on bodies", func() {
+		c := NewConnection(NewRequest(nil), NewJsonResponse(nil))
+		Expect(new(MultiParserHook).SetUp(c)).To(BeNil())
+		for _, contentType := range []string{CONTENT_TYPE_JSON, CONTENT_TYPE_XML, CONTENT_TYPE_FORM, CONTENT_TYPE_TEXT} {
+			_, ok := c.Request().Body().Parser(contentType)
+			Expect(ok).To(BeTrue())
+			_, ok = c.Response().Body().Parser(contentType)
+			Expect(ok).To(BeTrue())
+		}
+	})
+})
+
+var _ = Describe("FactoryParserManager", func() {
+	It("Parser should fall back to suffix, wildcard and default parser", func() {
+		json, xml, text := new(JsonParser), new(XmlParser), new(TextParser)
+		pm := NewParserManager().WithParser(json).WithParser(xml)
+
+		p, ok := pm.Parser("application/json; charset=utf-8")
+		Expect(ok).To(BeTrue())
+		Expect(p).To(Equal(json))
+		p, ok = pm.Parser("application/problem+json")
+		Expect(ok).To(BeTrue())
+		Expect(p).To(Equal(json))
+		p, ok = pm.Parser("application/atom+xml; type=feed")
+		Expect(ok).To(BeTrue())
+		Expect(p).To(Equal(xml))
+		_, ok = pm.Parser("text/csv")
+		Expect(ok).To(BeFalse())
+
+		pm.WithParser(&parserWildcard{})
+		p, ok = pm.Parser("text/csv")
+		Expect(ok).To(BeTrue())
+		Expect(p).To(Equal(&parserWildcard{}))
+
+		pm.WithDefaultParser(text)
+		p, ok = pm.Parser("image/png")
+		Expect(ok).To(BeTrue())
+		Expect(p).To(Equal(text))
+		Expect(pm.ContentTypes()).To(Equal([]string{CONTENT_TYPE_JSON, CONTENT_TYPE_XML, "text/*"}))
+	})
+})
+
+type parserWildcard struct {
+	TextParser
+}
+
+func (p *parserWildcard) ContentType() string {
+	return "text/*"
+}
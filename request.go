@@ -1,16 +1,22 @@
 package lapi
 
 import (
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
-	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/goline/errors"
 )
 
 // Request represents for an application's request
 type Request interface {
 	RequestBody
+	RequestBinder
 	RequestHeader
+	RequestMultipart
 	RequestCookies
 	RequestAncestor
 	RequestResolver
@@ -40,6 +46,25 @@ type RequestBody interface {
 	WithBody(body Body) Request
 }
 
+// RequestBinder fills a struct from request's parameters, see ParamBinder
+type RequestBinder interface {
+	// Bind sets target's fields from path, query, header and cookie parameters
+	Bind(target interface{}) errors.Error
+
+	// WithBinder sets binder
+	WithBinder(binder ParamBinder) Request
+}
+
+// RequestMultipart reads multipart/form-data body, such as file uploads
+type RequestMultipart interface {
+	// Multipart returns reader of request's multipart body, it is created once.
+	// It returns ERR_MULTIPART_INVALID if request's content is not multipart/form-data
+	Multipart() (Multipart, errors.Error)
+
+	// WithMultipartLimits sets limits of memory, disk and file's size, see DefaultMultipartLimits
+	WithMultipartLimits(limits MultipartLimits) Request
+}
+
 // RequestResolver returns routing information
 type RequestResolver interface {
 	// Route returns matched route for request
@@ -124,6 +149,7 @@ func NewRequest(req *http.Request) Request {
 		cookies:  make(map[string]*http.Cookie),
 		params:   NewBag(),
 		header:   NewHeader(),
+		limits:   DefaultMultipartLimits,
 	}
 	if req != nil {
 		r.body = NewBody(req.Body, nil)
@@ -135,19 +161,22 @@ func NewRequest(req *http.Request) Request {
 }
 
 type FactoryRequest struct {
-	id       string
-	ancestor *http.Request
-	header   Header
-	input    interface{}
-	cookies  map[string]*http.Cookie
-	params   Bag
-	route    Route
-	method   string
-	scheme   string
-	host     string
-	port     int
-	uri      string
-	body     Body
+	id        string
+	ancestor  *http.Request
+	header    Header
+	input     interface{}
+	cookies   map[string]*http.Cookie
+	params    Bag
+	route     Route
+	method    string
+	scheme    string
+	host      string
+	port      int
+	uri       string
+	body      Body
+	binder    ParamBinder
+	multipart Multipart
+	limits    MultipartLimits
 }
 
 func (r *FactoryRequest) Ancestor() *http.Request {
@@ -172,6 +201,74 @@ func (r *FactoryRequest) WithBody(body Body) Request {
 	return r
 }
 
+func (r *FactoryRequest) Bind(target interface{}) errors.Error {
+	if r.binder == nil {
+		r.binder = NewParamBinder()
+	}
+
+	return r.binder.Bind(r, target)
+}
+
+func (r *FactoryRequest) WithBinder(binder ParamBinder) Request {
+	r.binder = binder
+	return r
+}
+
+func (r *FactoryRequest) Multipart() (Multipart, errors.Error) {
+	if r.multipart == nil {
+		m, err := NewMultipart(r.limitedAncestor(), r.limits)
+		if err != nil {
+			return nil, err
+		}
+		r.multipart = m
+	}
+
+	return r.multipart, nil
+}
+
+// limitedAncestor returns ancestor which body is limited by body's MaxSize
+func (r *FactoryRequest) limitedAncestor() *http.Request {
+	if r.ancestor == nil || r.ancestor.Body == nil || r.body.MaxSize() <= 0 {
+		return r.ancestor
+	}
+
+	req := new(http.Request)
+	*req = *r.ancestor
+	req.Body = &limitedBody{
+		limitedReader: &limitedReader{
+			reader:    r.ancestor.Body,
+			remaining: r.body.MaxSize(),
+			err: errors.New(ERR_HTTP_REQUEST_ENTITY_TOO_LARGE, fmt.Sprintf("Content exceeds %d bytes", r.body.MaxSize())).
+				WithStatus(http.StatusRequestEntityTooLarge),
+		},
+		Closer: r.ancestor.Body,
+	}
+	return req
+}
+
+// limitedBody closes body which is read through limitedReader
+type limitedBody struct {
+	*limitedReader
+	io.Closer
+}
+
+func (r *FactoryRequest) WithMultipartLimits(limits MultipartLimits) Request {
+	r.limits = limits
+	return r
+}
+
+// Dispose removes temporary files of multipart body
+func (r *FactoryRequest) Dispose() error {
+	if r.multipart == nil {
+		return nil
+	}
+
+	if err := r.multipart.RemoveAll(); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (r *FactoryRequest) Route() Route {
 	return r.route
 }
@@ -274,6 +371,7 @@ func (r *FactoryRequest) Params() Bag {
 
 func (r *FactoryRequest) parseRequest() {
 	r.parseContentType()
+	r.body.WithContentEncoding(r.ancestor.Header.Get(HEADER_CONTENT_ENCODING))
 	r.parseRequestAddress()
 	r.parseRequestHeader()
 	r.parseCookies()
@@ -316,18 +414,18 @@ func (r *FactoryRequest) parseContentType() {
 	contentType, ok := r.header.Get(HEADER_CONTENT_TYPE)
 	if ok == false {
 		r.body.WithContentType(CONTENT_TYPE_DEFAULT).WithCharset(CONTENT_CHARSET_DEFAULT)
-	} else {
-		reg, err := regexp.Compile(`^([\w-/]+)(;?[ ]+charset=([\w-]+))?$`)
-		PanicOnError(err)
-		matches := reg.FindStringSubmatch(contentType)
-		switch len(matches) {
-		case 4:
-			if matches[3] == "" {
-				matches[3] = CONTENT_CHARSET_DEFAULT
-			}
-			r.body.WithContentType(matches[1]).WithCharset(matches[3])
-		default:
-			r.body.WithContentType(CONTENT_TYPE_DEFAULT).WithCharset(CONTENT_CHARSET_DEFAULT)
-		}
+		return
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || strings.Contains(mediaType, "/") == false {
+		r.body.WithContentType(CONTENT_TYPE_DEFAULT).WithCharset(CONTENT_CHARSET_DEFAULT)
+		return
+	}
+
+	charset := params["charset"]
+	if charset == "" {
+		charset = CONTENT_CHARSET_DEFAULT
 	}
+	r.body.WithContentType(mediaType).WithCharset(charset)
 }
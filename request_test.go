@@ -200,6 +200,11 @@ var _ = Describe("FactoryRequest", func() {
 		Expect(r.Body().ContentType()).To(Equal("application/json"))
 		Expect(r.Body().Charset()).To(Equal("UTF-8"))
 
+		r.header.Set(HEADER_CONTENT_TYPE, `application/vnd.api+json; profile="a;b"; charset=utf-16`)
+		r.parseContentType()
+		Expect(r.Body().ContentType()).To(Equal("application/vnd.api+json"))
+		Expect(r.Body().Charset()).To(Equal("utf-16"))
+
 		r.header.Set(HEADER_CONTENT_TYPE, "*invalid_content_type")
 		r.parseContentType()
 		Expect(r.Body().ContentType()).To(Equal(CONTENT_TYPE_DEFAULT))
@@ -3,6 +3,8 @@ package lapi
 import (
 	"fmt"
 	"net/http"
+	"reflect"
+	"strings"
 
 	"github.com/goline/errors"
 )
@@ -15,7 +17,28 @@ type Rescuer interface {
 	Rescue(connection Connection, v interface{}) error
 }
 
-func NewRescuer() Rescuer {
+// ErrorMapper is a Rescuer mapping errors to HTTP statuses and public messages.
+// Error's own message is kept if mapping's message is empty.
+// FactoryRescuer and ProblemRescuer implement it, see NewRescuer and NewProblemRescuer
+type ErrorMapper interface {
+	Rescuer
+
+	// MapCode maps an error code, such as ERR_HTTP_NOT_FOUND
+	MapCode(code string, status int, message string) ErrorMapper
+
+	// MapCodePrefix maps a range of error codes, such as "1.200." or "1.200.*". The longest prefix wins
+	MapCodePrefix(prefix string, status int, message string) ErrorMapper
+
+	// MapType maps errors having the same Go type as err, such as (*os.PathError)(nil).
+	// Wrapped errors are unwrapped to find a mapped type
+	MapType(err error, status int, message string) ErrorMapper
+
+	// WithGenericMessages lets unmapped errors which end up with 5xx statuses get a generic message,
+	// so internal details are not leaked to clients. It is enabled by default
+	WithGenericMessages(generic bool) ErrorMapper
+}
+
+func NewRescuer() ErrorMapper {
 	return &FactoryRescuer{}
 }
 
@@ -27,10 +50,34 @@ type ErrorResponse struct {
 	// The error message
 	// Required: true
 	Message string `json:"message"`
+
+	// The invalid fields
+	Errors []FieldError `json:"errors,omitempty"`
 }
 
 type FactoryRescuer struct {
-	parser Parser
+	parser   Parser
+	mappings errorMappings
+}
+
+func (r *FactoryRescuer) MapCode(code string, status int, message string) ErrorMapper {
+	r.mappings.mapCode(code, status, message)
+	return r
+}
+
+func (r *FactoryRescuer) MapCodePrefix(prefix string, status int, message string) ErrorMapper {
+	r.mappings.mapCodePrefix(prefix, status, message)
+	return r
+}
+
+func (r *FactoryRescuer) MapType(err error, status int, message string) ErrorMapper {
+	r.mappings.mapType(err, status, message)
+	return r
+}
+
+func (r *FactoryRescuer) WithGenericMessages(generic bool) ErrorMapper {
+	r.mappings.verbose = generic == false
+	return r
 }
 
 func (r *FactoryRescuer) Rescue(c Connection, v interface{}) error {
@@ -44,33 +91,244 @@ func (r *FactoryRescuer) Rescue(c Connection, v interface{}) error {
 		WithContentType(CONTENT_TYPE_JSON).
 		WithParser(r.parser)
 
-	var code, message string
+	code, message, fields, _ := inspectError(c, v, &r.mappings)
+	if err := c.Response().Body().Write(&ErrorResponse{code, message, fields}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// inspectError sets response's status from v, then returns its code, public message, invalid fields and debug information
+func inspectError(c Connection, v interface{}, mappings *errorMappings) (code string, message string, fields []FieldError, debug interface{}) {
 	code = ERR_HTTP_UNKNOWN_ERROR
+	if e, ok := v.(FieldErrors); ok == true {
+		fields = e.Fields()
+	}
+
+	var status int
 	if e, ok := v.(errors.Error); ok == true {
 		code = e.Code()
-		switch code {
-		case ERR_HTTP_NOT_FOUND:
-			c.Response().WithStatus(http.StatusNotFound)
-		case ERR_HTTP_BAD_REQUEST:
-			c.Response().WithStatus(http.StatusBadRequest)
-		case ERR_HTTP_INTERNAL_SERVER_ERROR:
-			c.Response().WithStatus(http.StatusInternalServerError)
-		default:
-			if e.Status() == http.StatusOK {
-				c.Response().WithStatus(http.StatusInternalServerError)
-			} else if c.Response().Status() == http.StatusOK {
-				c.Response().WithStatus(e.Status())
+		message = e.Message()
+		debug = e.Debug()
+		status = statusOfCode(code)
+		if status == 0 && e.Status() != http.StatusOK {
+			status = e.Status()
+			if c.Response().Status() != http.StatusOK {
+				// keep status which is set before error happens
+				status = c.Response().Status()
 			}
 		}
-		message = e.Message()
 	} else if e, ok := v.(error); ok == true {
 		message = e.Error()
-		c.Response().WithStatus(http.StatusInternalServerError)
 	} else {
 		message = fmt.Sprintf("%s", v)
-		c.Response().WithStatus(http.StatusInternalServerError)
 	}
-	if err := c.Response().Body().Write(&ErrorResponse{code, message}); err != nil {
+
+	mapping, mapped := mappings.lookup(code, v)
+	if mapped == true {
+		status = mapping.status
+	}
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	c.Response().WithStatus(status)
+
+	if mapped == true && mapping.message != "" {
+		message = mapping.message
+	} else if mapped == false && mappings.verbose == false && status >= http.StatusInternalServerError {
+		if debug == nil {
+			debug = message
+		}
+		message = http.StatusText(status)
+	}
+	return code, message, fields, debug
+}
+
+// statusOfCode returns status of built-in error codes, it returns 0 for others
+func statusOfCode(code string) int {
+	switch code {
+	case ERR_HTTP_NOT_FOUND:
+		return http.StatusNotFound
+	case ERR_HTTP_METHOD_NOT_ALLOWED:
+		return http.StatusMethodNotAllowed
+	case ERR_HTTP_BAD_REQUEST:
+		return http.StatusBadRequest
+	case ERR_HTTP_NOT_ACCEPTABLE:
+		return http.StatusNotAcceptable
+	case ERR_HTTP_REQUEST_ENTITY_TOO_LARGE:
+		return http.StatusRequestEntityTooLarge
+	case ERR_HTTP_PRECONDITION_FAILED:
+		return http.StatusPreconditionFailed
+	case ERR_HTTP_INTERNAL_SERVER_ERROR:
+		return http.StatusInternalServerError
+	default:
+		return 0
+	}
+}
+
+type errorMapping struct {
+	status  int
+	message string
+}
+
+// errorMappings keeps mappings of ErrorMapper, its zero value is ready to use
+type errorMappings struct {
+	codes    map[string]*errorMapping
+	prefixes map[string]*errorMapping
+	types    map[reflect.Type]*errorMapping
+
+	// verbose lets messages of unmapped internal errors be rendered, generic messages are used otherwise
+	verbose bool
+}
+
+func (m *errorMappings) mapCode(code string, status int, message string) {
+	if m.codes == nil {
+		m.codes = make(map[string]*errorMapping)
+	}
+	m.codes[code] = &errorMapping{status, message}
+}
+
+func (m *errorMappings) mapCodePrefix(prefix string, status int, message string) {
+	if m.prefixes == nil {
+		m.prefixes = make(map[string]*errorMapping)
+	}
+	m.prefixes[strings.TrimSuffix(prefix, "*")] = &errorMapping{status, message}
+}
+
+func (m *errorMappings) mapType(err error, status int, message string) {
+	if m.types == nil {
+		m.types = make(map[reflect.Type]*errorMapping)
+	}
+	m.types[reflect.TypeOf(err)] = &errorMapping{status, message}
+}
+
+// lookup finds mapping of code, then of code's prefix, then of v's type
+func (m *errorMappings) lookup(code string, v interface{}) (*errorMapping, bool) {
+	if mapping, ok := m.codes[code]; ok == true {
+		return mapping, true
+	}
+
+	var found *errorMapping
+	longest := -1
+	for prefix, mapping := range m.prefixes {
+		if strings.HasPrefix(code, prefix) == true && len(prefix) > longest {
+			found, longest = mapping, len(prefix)
+		}
+	}
+	if found != nil {
+		return found, true
+	}
+
+	for v != nil && len(m.types) > 0 {
+		if mapping, ok := m.types[reflect.TypeOf(v)]; ok == true {
+			return mapping, true
+		}
+
+		w, ok := v.(interface{ Unwrap() error })
+		if ok == false || w.Unwrap() == nil {
+			break
+		}
+		v = w.Unwrap()
+	}
+	return nil, false
+}
+
+// ProblemDetails is an error response of RFC 7807, extended with error's code and invalid fields
+type ProblemDetails struct {
+	// A URI reference identifying problem type, it is about:blank by default
+	Type string `json:"type"`
+
+	// A short summary of problem type
+	Title string `json:"title"`
+
+	// The HTTP status code
+	Status int `json:"status"`
+
+	// An explanation specific to this occurrence of problem
+	Detail string `json:"detail,omitempty"`
+
+	// A URI reference identifying this occurrence of problem
+	Instance string `json:"instance,omitempty"`
+
+	// The error code
+	Code string `json:"code"`
+
+	// The invalid fields
+	Errors []FieldError `json:"errors,omitempty"`
+
+	// Debug information of error, it is rendered only if rescuer's debug is enabled
+	Debug interface{} `json:"debug,omitempty"`
+}
+
+// NewProblemRescuer returns a Rescuer writing application/problem+json as of RFC 7807
+func NewProblemRescuer() ErrorMapper {
+	return &ProblemRescuer{}
+}
+
+type ProblemRescuer struct {
+	// TypeBase is prefix of problem's type, followed by error's code, such as https://example.com/errors/0.002.002.
+	// Problem's type is about:blank if it is empty
+	TypeBase string
+
+	// Debug lets debug information of errors be rendered, it must not be enabled in production
+	Debug bool
+
+	parser   Parser
+	mappings errorMappings
+}
+
+func (r *ProblemRescuer) MapCode(code string, status int, message string) ErrorMapper {
+	r.mappings.mapCode(code, status, message)
+	return r
+}
+
+func (r *ProblemRescuer) MapCodePrefix(prefix string, status int, message string) ErrorMapper {
+	r.mappings.mapCodePrefix(prefix, status, message)
+	return r
+}
+
+func (r *ProblemRescuer) MapType(err error, status int, message string) ErrorMapper {
+	r.mappings.mapType(err, status, message)
+	return r
+}
+
+func (r *ProblemRescuer) WithGenericMessages(generic bool) ErrorMapper {
+	r.mappings.verbose = generic == false
+	return r
+}
+
+func (r *ProblemRescuer) Rescue(c Connection, v interface{}) error {
+	if c == nil {
+		return errors.New(ERR_INVALID_ARGUMENT, "Connection must be not nil")
+	}
+	if r.parser == nil {
+		r.parser = new(JsonParser)
+	}
+	c.Response().Body().
+		WithContentType(CONTENT_TYPE_PROBLEM).
+		WithParser(r.parser)
+
+	code, message, fields, debug := inspectError(c, v, &r.mappings)
+	problem := &ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(c.Response().Status()),
+		Status: c.Response().Status(),
+		Detail: message,
+		Code:   code,
+		Errors: fields,
+	}
+	if r.TypeBase != "" {
+		problem.Type = r.TypeBase + code
+	}
+	if c.Request() != nil {
+		problem.Instance = c.Request().Uri()
+	}
+	if r.Debug == true {
+		problem.Debug = debug
+	}
+
+	if err := c.Response().Body().Write(problem); err != nil {
 		return err
 	}
 
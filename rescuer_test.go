@@ -1,10 +1,13 @@
 package lapi
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/goline/errors"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"net/http"
+	"os"
 )
 
 var _ = Describe("Rescuer", func() {
@@ -35,6 +38,14 @@ var _ = Describe("FactoryRescuer", func() {
 		Expect(c.Response().Status()).To(Equal(http.StatusBadRequest))
 	})
 
+	It("Rescue set http status to StatusMethodNotAllowed", func() {
+		c := NewConnection(nil, getEmptyResponse())
+		e := errors.New(ERR_HTTP_METHOD_NOT_ALLOWED, "")
+		h := &FactoryRescuer{}
+		h.Rescue(c, e)
+		Expect(c.Response().Status()).To(Equal(http.StatusMethodNotAllowed))
+	})
+
 	It("Rescue set http status to StatusInternalServerError for unknown error", func() {
 		c := NewConnection(nil, getEmptyResponse())
 		e := errors.New("11", "err1")
@@ -43,6 +54,16 @@ var _ = Describe("FactoryRescuer", func() {
 		Expect(c.Response().Status()).To(Equal(http.StatusInternalServerError))
 	})
 
+	It("Rescue should render invalid fields", func() {
+		c := NewConnection(nil, getEmptyResponse())
+		e := NewFieldErrors([]FieldError{{"name", "", "is required"}})
+		h := &FactoryRescuer{}
+		h.Rescue(c, e)
+		Expect(c.Response().Status()).To(Equal(http.StatusBadRequest))
+		Expect(string(c.Response().Body().(*FactoryBody).contentBytes)).To(Equal(
+			`{"code":"0.002.002","message":"Invalid parameters: name is required","errors":[{"field":"name","message":"is required"}]}`))
+	})
+
 	It("Rescue will not handle this case", func() {
 		e := &myUnknownError{}
 		h := &FactoryRescuer{}
@@ -51,6 +72,109 @@ var _ = Describe("FactoryRescuer", func() {
 	})
 })
 
+var _ = Describe("FactoryRescuer ErrorMapper", func() {
+	rescue := func(r Rescuer, v interface{}) (int, *ErrorResponse) {
+		c := NewConnection(nil, getEmptyResponse())
+		Expect(r.Rescue(c, v)).To(BeNil())
+		res := new(ErrorResponse)
+		Expect(json.Unmarshal(c.Response().Body().Bytes(), res)).To(BeNil())
+		return c.Response().Status(), res
+	}
+
+	It("should map codes, code prefixes and Go types", func() {
+		r := NewRescuer().
+			MapCode("1.200.001", http.StatusConflict, "").
+			MapCodePrefix("1.200.*", http.StatusUnprocessableEntity, "Invalid order").
+			MapCodePrefix("1.", http.StatusServiceUnavailable, "").
+			MapCode(ERR_HTTP_NOT_FOUND, http.StatusGone, "Gone").
+			MapType(&os.PathError{}, http.StatusNotFound, "File not found")
+
+		status, res := rescue(r, errors.New("1.200.001", "Order exists"))
+		Expect(status).To(Equal(http.StatusConflict))
+		Expect(res.Message).To(Equal("Order exists"))
+
+		status, res = rescue(r, errors.New("1.200.002", "Quantity is negative"))
+		Expect(status).To(Equal(http.StatusUnprocessableEntity))
+		Expect(res.Message).To(Equal("Invalid order"))
+
+		status, res = rescue(r, errors.New("1.300.001", "Database is down"))
+		Expect(status).To(Equal(http.StatusServiceUnavailable))
+		Expect(res.Message).To(Equal("Database is down"))
+
+		status, res = rescue(r, errors.New(ERR_HTTP_NOT_FOUND, "Not found"))
+		Expect(status).To(Equal(http.StatusGone))
+		Expect(res.Message).To(Equal("Gone"))
+
+		status, res = rescue(r, fmt.Errorf("loading: %w", &os.PathError{Op: "open", Path: "/etc/secret", Err: os.ErrNotExist}))
+		Expect(status).To(Equal(http.StatusNotFound))
+		Expect(res.Message).To(Equal("File not found"))
+	})
+
+	It("should hide messages of unmapped internal errors unless generic messages are disabled", func() {
+		err := fmt.Errorf("dial tcp 10.0.0.1:5432: connection refused")
+		status, res := rescue(NewRescuer().WithGenericMessages(false), err)
+		Expect(status).To(Equal(http.StatusInternalServerError))
+		Expect(res.Message).To(Equal(err.Error()))
+
+		r := NewRescuer()
+		status, res = rescue(r, err)
+		Expect(status).To(Equal(http.StatusInternalServerError))
+		Expect(res.Code).To(Equal(ERR_HTTP_UNKNOWN_ERROR))
+		Expect(res.Message).To(Equal("Internal Server Error"))
+
+		status, res = rescue(r, errors.New(ERR_HTTP_BAD_REQUEST, "Name is required"))
+		Expect(status).To(Equal(http.StatusBadRequest))
+		Expect(res.Message).To(Equal("Name is required"))
+	})
+})
+
 func getEmptyResponse() Response {
 	return &FactoryResponse{body: NewBody(nil, nil)}
 }
+
+var _ = Describe("ProblemRescuer", func() {
+	It("NewProblemRescuer should return an instance of Rescuer", func() {
+		Expect(NewProblemRescuer()).NotTo(BeNil())
+	})
+
+	It("Rescue should write problem details", func() {
+		req, _ := http.NewRequest("POST", "/users", nil)
+		c := NewConnection(NewRequest(req), getEmptyResponse())
+		e := NewFieldErrors([]FieldError{{"name", "", "is required"}})
+		h := &ProblemRescuer{TypeBase: "https://example.com/errors/"}
+		Expect(h.Rescue(c, e)).To(BeNil())
+		Expect(c.Response().Status()).To(Equal(http.StatusBadRequest))
+		Expect(c.Response().Body().ContentType()).To(Equal(CONTENT_TYPE_PROBLEM))
+		Expect(string(c.Response().Body().Bytes())).To(Equal(`{"type":"https://example.com/errors/0.002.002",` +
+			`"title":"Bad Request","status":400,"detail":"Invalid parameters: name is required",` +
+			`"instance":"/users","code":"0.002.002","errors":[{"field":"name","message":"is required"}]}`))
+	})
+
+	It("Rescue should render debug information only if it is enabled", func() {
+		e := errors.New("11", "err1").WithDebug("stack")
+		c := NewConnection(nil, getEmptyResponse())
+		Expect((&ProblemRescuer{}).Rescue(c, e)).To(BeNil())
+		Expect(string(c.Response().Body().Bytes())).To(Equal(
+			`{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"Internal Server Error","code":"11"}`))
+
+		c = NewConnection(nil, getEmptyResponse())
+		Expect((&ProblemRescuer{Debug: true}).Rescue(c, e)).To(BeNil())
+		Expect(string(c.Response().Body().Bytes())).To(ContainSubstring(`"debug":"stack"`))
+
+		c = NewConnection(nil, getEmptyResponse())
+		Expect((&ProblemRescuer{Debug: true}).Rescue(c, errors.New("11", "err1"))).To(BeNil())
+		Expect(string(c.Response().Body().Bytes())).To(ContainSubstring(`"debug":"err1"`))
+	})
+})
+
+type rescuerCustom struct{}
+
+func (r *rescuerCustom) Rescue(c Connection, v interface{}) error { return nil }
+
+var _ = Describe("Custom Rescuer", func() {
+	It("should not be required to implement ErrorMapper", func() {
+		app := NewApp().WithRescuer(&rescuerCustom{})
+		_, ok := app.Rescuer().(ErrorMapper)
+		Expect(ok).To(BeFalse())
+	})
+})
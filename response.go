@@ -1,6 +1,7 @@
 package lapi
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -12,6 +13,7 @@ type Response interface {
 	ResponseBody
 	ResponseHeader
 	ResponseSender
+	ResponseStreamer
 	ResponseCookies
 	ResponseInformer
 	ResponseAncestor
@@ -74,6 +76,15 @@ type ResponseSender interface {
 	IsSent() bool
 }
 
+// ResponseStreamer sends response in chunks
+type ResponseStreamer interface {
+	// Stream sends status and header out, then returns a Stream which writes chunks to client directly.
+	// Response is considered as sent, so its body is not flushed anymore.
+	// Stream is done when ctx is done, such as request's context when client disconnects,
+	// or when handler returns as response is disposed
+	Stream(ctx context.Context) (Stream, errors.Error)
+}
+
 func NewResponse(w http.ResponseWriter) Response {
 	return &FactoryResponse{
 		ancestor: w,
@@ -107,6 +118,7 @@ type FactoryResponse struct {
 	body      Body
 	isSent    bool
 	isSending bool
+	stream    Stream
 }
 
 func (r *FactoryResponse) Ancestor() http.ResponseWriter {
@@ -172,6 +184,59 @@ func (r *FactoryResponse) Send() errors.Error {
 		return errors.New(ERR_RESPONSE_ALREADY_SENT, "Response is already sent")
 	}
 
+	r.writeHeader()
+	if r.message != "" {
+		http.Error(r.ancestor, r.message, r.status)
+	} else {
+		r.ancestor.WriteHeader(r.status)
+	}
+
+	if err := r.body.Flush(); err != nil {
+		return err
+	}
+
+	r.isSent = true
+	return nil
+}
+
+func (r *FactoryResponse) Stream(ctx context.Context) (Stream, errors.Error) {
+	if r.lock() == true {
+		return nil, errors.New(ERR_RESPONSE_IS_SENDING, "Sending response is in progress")
+	}
+	defer r.unlock()
+
+	if r.ancestor == nil {
+		return nil, errors.New(ERR_NO_WRITER_FOUND, "No writer found")
+	}
+
+	if r.isSent {
+		return nil, errors.New(ERR_RESPONSE_ALREADY_SENT, "Response is already sent")
+	}
+
+	flusher, ok := r.ancestor.(http.Flusher)
+	if ok == false {
+		return nil, errors.New(ERR_STREAM_NOT_SUPPORTED, fmt.Sprintf("%T does not support flushing", r.ancestor))
+	}
+
+	r.writeHeader()
+	r.ancestor.WriteHeader(r.status)
+	flusher.Flush()
+
+	r.isSent = true
+	r.stream = newStream(ctx, r.ancestor, flusher, r.body, r.body.ContentType())
+	return r.stream, nil
+}
+
+// Dispose closes stream of response, so nothing is written once request is served
+func (r *FactoryResponse) Dispose() error {
+	if r.stream != nil {
+		r.stream.Close()
+	}
+	return nil
+}
+
+// writeHeader copies content-type, cookies and header to ancestor
+func (r *FactoryResponse) writeHeader() {
 	contentType := r.body.ContentType()
 	if contentType != "" {
 		charset := r.body.Charset()
@@ -189,19 +254,6 @@ func (r *FactoryResponse) Send() errors.Error {
 	for k, v := range r.header.All() {
 		r.ancestor.Header().Set(k, v)
 	}
-
-	if r.message != "" {
-		http.Error(r.ancestor, r.message, r.status)
-	} else {
-		r.ancestor.WriteHeader(r.status)
-	}
-
-	if err := r.body.Flush(); err != nil {
-		return err
-	}
-
-	r.isSent = true
-	return nil
 }
 
 func (r *FactoryResponse) IsSent() bool {
@@ -1,6 +1,7 @@
 package lapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -200,3 +201,47 @@ var _ = Describe("FactoryResponse", func() {
 		Expect(r.body).NotTo(BeNil())
 	})
 })
+
+var _ = Describe("FactoryResponse Stream", func() {
+	It("Stream should write and flush chunks", func() {
+		rw := httptest.NewRecorder()
+		r := NewJsonResponse(rw)
+		r.Body().WithParser(new(JsonParser))
+		r.WithStatus(http.StatusAccepted)
+		stream, err := r.Stream(context.Background())
+		Expect(err).To(BeNil())
+		Expect(r.IsSent()).To(BeTrue())
+		Expect(rw.Code).To(Equal(http.StatusAccepted))
+		Expect(rw.Flushed).To(BeTrue())
+
+		Expect(stream.Send(map[string]int{"a": 1})).To(BeNil())
+		Expect(stream.Send("\n")).To(BeNil())
+		Expect(rw.Body.String()).To(Equal("{\"a\":1}\n"))
+
+		stream.Close()
+		_, e := stream.Write([]byte("x"))
+		Expect(e).NotTo(BeNil())
+		Expect(e.(errors.Error).Code()).To(Equal(ERR_STREAM_CLOSED))
+		Expect(r.Send().Code()).To(Equal(ERR_RESPONSE_ALREADY_SENT))
+	})
+
+	It("Stream should be done when context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, _ := NewResponse(httptest.NewRecorder()).Stream(ctx)
+		cancel()
+		Expect(stream.Done()).To(BeClosed())
+		Expect(stream.Send("x").Code()).To(Equal(ERR_STREAM_CLOSED))
+	})
+
+	It("Stream should return error code ERR_STREAM_NOT_SUPPORTED", func() {
+		_, err := NewResponse(&sampleResponseWriter{}).Stream(context.Background())
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_STREAM_NOT_SUPPORTED))
+	})
+})
+
+type sampleResponseWriter struct{}
+
+func (w *sampleResponseWriter) Header() http.Header         { return http.Header{} }
+func (w *sampleResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *sampleResponseWriter) WriteHeader(status int)      {}
@@ -2,9 +2,16 @@ package lapi
 
 import (
 	"fmt"
+	"net/url"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goline/errors"
 )
 
 // Route acts a route describer
@@ -13,8 +20,19 @@ type Route interface {
 	RouteHooker
 	RouteHandler
 	RouteMatcher
+	RouteBuilder
 	RouteDescriber
 	RouteIdentifier
+	RouteLimiter
+}
+
+// RouteLimiter restricts request's body of route
+type RouteLimiter interface {
+	// BodyLimit returns maximum size of request's body in bytes, application's limit is used if 0
+	BodyLimit() int64
+
+	// WithBodyLimit sets maximum size of request's body in bytes
+	WithBodyLimit(size int64) Route
 }
 
 // RouteIdentifier identifies route
@@ -62,6 +80,13 @@ type RouteMatcher interface {
 	Match(request Request) (Route, bool)
 }
 
+// RouteBuilder generates url from route
+type RouteBuilder interface {
+	// URL fills route's placeholders with params, remaining params are added to query string.
+	// When route has a host, url will be in scheme-relative form, such as //en.domain.com/users/1
+	URL(params map[string]interface{}) (string, errors.Error)
+}
+
 // RouteHooker manages route's hooks
 type RouteHooker interface {
 	// Hooks returns all hooks for route
@@ -72,6 +97,12 @@ type RouteHooker interface {
 
 	// WithHook add a single hook
 	WithHook(hook Hook) Route
+
+	// SequentialHooks returns true if hooks run one after another in registration order
+	SequentialHooks() bool
+
+	// WithSequentialHooks lets hooks run one after another instead of in parallel
+	WithSequentialHooks(sequential bool) Route
 }
 
 // RouteTagger lets route become taggable
@@ -86,13 +117,25 @@ type RouteTagger interface {
 	WithTags(tags ...string) Route
 }
 
+// NewRoute returns an instance of Route. Placeholders could use built-in types:
+//
+//	<id:int>     integer, it is stored as int64
+//	<name:slug>  lowercase words joined by "-"
+//	<uid:uuid>   UUID
+//	<day:date>   date in form of 2006-01-02, it is stored as time.Time
 func NewRoute(method string, uri string, handler Handler) Route {
+	return newRoute(method, uri, handler, nil)
+}
+
+// newRoute returns a route which recognizes custom types of placeholders
+func newRoute(method string, uri string, handler Handler, types map[string]*ParamType) Route {
 	r := &FactoryRoute{
 		pvHost:     &patternVerifier{},
 		pvUri:      &patternVerifier{},
 		hooks:      make(map[int]*Slice),
 		tags:       make([]string, 0),
 		autoEnding: true,
+		types:      types,
 	}
 	return r.
 		WithMethod(method).
@@ -113,6 +156,9 @@ type FactoryRoute struct {
 	requestInput   reflect.Type
 	responseOutput reflect.Type
 	tags           []string
+	sequential     bool
+	types          map[string]*ParamType
+	bodyLimit      int64
 
 	// Automatically add ending character "$" to uri
 	autoEnding bool
@@ -121,9 +167,102 @@ type FactoryRoute struct {
 type patternVerifier struct {
 	pattern string
 	keys    []string
+	types   []*ParamType
 	reg     *regexp.Regexp
 }
 
+// ParamType describes a named type of placeholder, such as <id:int>
+type ParamType struct {
+	// Pattern is regular expression which placeholder's value must match
+	Pattern string
+
+	// Convert turns matched value into Go value, value is kept as string if Convert is nil.
+	// Placeholder does not match if an error is returned
+	Convert func(value string) (interface{}, error)
+
+	// Format turns Go value back to string for building url, fmt.Sprint is used if Format is nil
+	Format func(value interface{}) string
+}
+
+// paramTypes are built-in types of placeholders
+var paramTypes = map[string]*ParamType{
+	"int": {
+		Pattern: `-?\d+`,
+		Convert: func(value string) (interface{}, error) {
+			return strconv.ParseInt(value, 10, 64)
+		},
+	},
+	"slug": {Pattern: `[a-z0-9]+(?:-[a-z0-9]+)*`},
+	"uuid": {Pattern: `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`},
+	"date": {
+		Pattern: `\d{4}-\d{2}-\d{2}`,
+		Convert: func(value string) (interface{}, error) {
+			return time.Parse(PARAM_DATE_LAYOUT, value)
+		},
+		Format: func(value interface{}) string {
+			if t, ok := value.(time.Time); ok == true {
+				return t.Format(PARAM_DATE_LAYOUT)
+			}
+			return fmt.Sprint(value)
+		},
+	},
+}
+
+// match tests s, then converts values of typed placeholders
+func (pv *patternVerifier) match(s string) bool {
+	if len(pv.types) == 0 {
+		return pv.reg.MatchString(s)
+	}
+
+	_, ok := pv.values(s)
+	return ok
+}
+
+// matchStart tests s as match does, but the match must begin at the start of s
+func (pv *patternVerifier) matchStart(s string) bool {
+	loc := pv.reg.FindStringIndex(s)
+	return loc != nil && loc[0] == 0 && pv.match(s)
+}
+
+// values returns values of placeholders, values of typed placeholders are converted
+func (pv *patternVerifier) values(s string) ([]interface{}, bool) {
+	m := pv.reg.FindStringSubmatch(s)
+	if len(m) != len(pv.keys)+1 {
+		return nil, false
+	}
+
+	values := make([]interface{}, len(pv.keys))
+	for i := range pv.keys {
+		values[i] = m[i+1]
+		if len(pv.types) == 0 {
+			continue
+		}
+
+		value, err := pv.types[i].convert(m[i+1])
+		if err != nil {
+			return nil, false
+		}
+		values[i] = value
+	}
+	return values, true
+}
+
+func (t *ParamType) convert(value string) (interface{}, error) {
+	if t == nil || t.Convert == nil {
+		return value, nil
+	}
+
+	return t.Convert(value)
+}
+
+func (t *ParamType) format(value interface{}) string {
+	if t == nil || t.Format == nil {
+		return fmt.Sprint(value)
+	}
+
+	return t.Format(value)
+}
+
 func (r *FactoryRoute) Name() string {
 	return r.name
 }
@@ -149,7 +288,7 @@ func (r *FactoryRoute) Host() string {
 func (r *FactoryRoute) WithHost(host string) Route {
 	var err error
 	r.host = host
-	r.pvHost.pattern, r.pvHost.keys = r.extractKeyPattern(host)
+	r.pvHost.pattern, r.pvHost.keys, r.pvHost.types = r.extractKeyPattern(host)
 	r.pvHost.reg, err = regexp.Compile(r.pvHost.pattern)
 	PanicOnError(err)
 	return r
@@ -162,11 +301,12 @@ func (r *FactoryRoute) Uri() string {
 func (r *FactoryRoute) WithUri(uri string) Route {
 	var err error
 	r.uri = uri
+	atomic.AddUint64(&routeRevision, 1)
 	if r.autoEnding && uri[len(uri)-1:] != "$" {
 		uri = uri + "$"
 	}
 
-	r.pvUri.pattern, r.pvUri.keys = r.extractKeyPattern(uri)
+	r.pvUri.pattern, r.pvUri.keys, r.pvUri.types = r.extractKeyPattern(uri)
 	r.pvUri.reg, err = regexp.Compile(r.pvUri.pattern)
 	PanicOnError(err)
 	return r
@@ -178,6 +318,16 @@ func (r *FactoryRoute) Handler() Handler {
 
 func (r *FactoryRoute) WithHandler(handler Handler) Route {
 	r.handler = handler
+	r.requestInput, r.responseOutput = nil, nil
+	if h, ok := handler.(IOHandler); ok == true {
+		input, output := h.IO()
+		if input != nil {
+			r.requestInput = reflect.TypeOf(input)
+		}
+		if output != nil {
+			r.responseOutput = reflect.TypeOf(output)
+		}
+	}
 	return r
 }
 
@@ -207,6 +357,24 @@ func (r *FactoryRoute) WithHook(hook Hook) Route {
 	return r
 }
 
+func (r *FactoryRoute) SequentialHooks() bool {
+	return r.sequential
+}
+
+func (r *FactoryRoute) WithSequentialHooks(sequential bool) Route {
+	r.sequential = sequential
+	return r
+}
+
+func (r *FactoryRoute) BodyLimit() int64 {
+	return r.bodyLimit
+}
+
+func (r *FactoryRoute) WithBodyLimit(size int64) Route {
+	r.bodyLimit = size
+	return r
+}
+
 func (r *FactoryRoute) Match(request Request) (Route, bool) {
 	method := request.Method()
 	host := request.Host()
@@ -220,6 +388,41 @@ func (r *FactoryRoute) Match(request Request) (Route, bool) {
 	return r, true
 }
 
+func (r *FactoryRoute) URL(params map[string]interface{}) (string, errors.Error) {
+	used := make(map[string]bool)
+	uri := strings.TrimSuffix(strings.TrimPrefix(r.uri, "^"), "$")
+	uri, err := r.fillPattern(uri, params, used, true)
+	if err != nil {
+		return "", err
+	}
+
+	if r.host != "" {
+		host, err := r.fillPattern(r.host, params, used, false)
+		if err != nil {
+			return "", err
+		}
+		uri = "//" + host + uri
+	}
+
+	query := url.Values{}
+	for key, value := range params {
+		if used[key] == true {
+			continue
+		}
+
+		switch v := value.(type) {
+		case []string:
+			query[key] = v
+		default:
+			query.Add(key, fmt.Sprint(v))
+		}
+	}
+	if len(query) > 0 {
+		uri = uri + "?" + query.Encode()
+	}
+	return uri, nil
+}
+
 func (r *FactoryRoute) Tags() []string {
 	return r.tags
 }
@@ -238,20 +441,36 @@ func (r *FactoryRoute) genRouteName() string {
 	return fmt.Sprintf("%s_%s", r.Method(), strings.Replace(r.Uri(), "/", "_", -1))
 }
 
-func (r *FactoryRoute) extractKeyPattern(pattern string) (string, []string) {
-	p := `(\<(\w+):([^\>]+)\>)`
-	re, err := regexp.Compile(p)
-	PanicOnError(err)
-	if !re.MatchString(pattern) {
-		return pattern, make([]string, 0)
+func (r *FactoryRoute) extractKeyPattern(pattern string) (string, []string, []*ParamType) {
+	if !routeKeyRegexp.MatchString(pattern) {
+		return pattern, make([]string, 0), nil
 	}
-	v := re.FindAllStringSubmatch(pattern, -1)
+	v := routeKeyRegexp.FindAllStringSubmatch(pattern, -1)
 	keys := make([]string, len(v))
+	types := make([]*ParamType, len(v))
+	typed := false
 	for i, m := range v {
 		keys[i] = m[2]
-		pattern = strings.Replace(pattern, m[1], fmt.Sprintf("(%s)", m[3]), 1)
+		p, t := r.paramPattern(m[3])
+		types[i] = t
+		typed = typed || t != nil
+		pattern = strings.Replace(pattern, m[1], fmt.Sprintf("(%s)", p), 1)
+	}
+	if typed == false {
+		types = nil
+	}
+	return pattern, keys, types
+}
+
+// paramPattern resolves placeholder's pattern, which is either a regular expression or a type
+func (r *FactoryRoute) paramPattern(pattern string) (string, *ParamType) {
+	if t, ok := r.types[pattern]; ok == true {
+		return t.Pattern, t
+	}
+	if t, ok := paramTypes[pattern]; ok == true {
+		return t.Pattern, t
 	}
-	return pattern, keys
+	return pattern, nil
 }
 
 func (r *FactoryRoute) matchMethod(method string) bool {
@@ -267,7 +486,7 @@ func (r *FactoryRoute) matchHost(host string) bool {
 		return true
 	}
 
-	return r.pvHost.reg.MatchString(host)
+	return r.pvHost.match(host)
 }
 
 func (r *FactoryRoute) matchUri(uri string) bool {
@@ -275,7 +494,7 @@ func (r *FactoryRoute) matchUri(uri string) bool {
 		return false
 	}
 
-	return r.pvUri.reg.MatchString(uri)
+	return r.pvUri.match(uri)
 }
 
 func (r *FactoryRoute) modifyRequestOnMatch(request Request, pv *patternVerifier, s string) {
@@ -284,12 +503,63 @@ func (r *FactoryRoute) modifyRequestOnMatch(request Request, pv *patternVerifier
 		return
 	}
 
-	m := pv.reg.FindStringSubmatch(s)
-	numMatches := len(m)
-	if numKeys+1 != numMatches {
+	values, ok := pv.values(s)
+	if ok == false {
 		return
 	}
 	for i, key := range pv.keys {
-		request.WithParam(key, m[i+1])
+		request.WithParam(key, values[i])
+	}
+}
+
+// routeRevision changes whenever uri of a route changes, so routers rebuild their trees
+var routeRevision uint64
+
+// placeholderRegexps caches compiled patterns of placeholders for building urls
+var placeholderRegexps = new(sync.Map)
+
+func (r *FactoryRoute) fillPattern(pattern string, params map[string]interface{}, used map[string]bool, escape bool) (string, errors.Error) {
+	matches := routeKeyRegexp.FindAllStringSubmatchIndex(pattern, -1)
+	s := ""
+	last := 0
+	for _, m := range matches {
+		static := pattern[last:m[0]]
+		if isStaticUri(static) == false {
+			return "", errors.New(ERR_ROUTE_NOT_REVERSIBLE, fmt.Sprintf("Route %s contains regular expression out of placeholders", r.name))
+		}
+
+		key := pattern[m[4]:m[5]]
+		value, ok := params[key]
+		if ok == false {
+			return "", errors.New(ERR_ROUTE_MISSING_PARAMETER, fmt.Sprintf("Route %s requires parameter %s", r.name, key))
+		}
+
+		p, t := r.paramPattern(pattern[m[6]:m[7]])
+		v := t.format(value)
+		if r.placeholderRegexp(p).MatchString(v) == false {
+			return "", errors.New(ERR_ROUTE_INVALID_PARAMETER, fmt.Sprintf("Parameter %s of route %s does not match %s. Got %s", key, r.name, p, v))
+		}
+
+		if escape == true {
+			v = strings.Replace(url.PathEscape(v), "%2F", "/", -1)
+		}
+		s = s + static + v
+		used[key] = true
+		last = m[1]
+	}
+
+	if isStaticUri(pattern[last:]) == false {
+		return "", errors.New(ERR_ROUTE_NOT_REVERSIBLE, fmt.Sprintf("Route %s contains regular expression out of placeholders", r.name))
 	}
+	return s + pattern[last:], nil
+}
+
+func (r *FactoryRoute) placeholderRegexp(pattern string) *regexp.Regexp {
+	if reg, ok := placeholderRegexps.Load(pattern); ok == true {
+		return reg.(*regexp.Regexp)
+	}
+
+	reg := regexp.MustCompile("^(?:" + pattern + ")$")
+	placeholderRegexps.Store(pattern, reg)
+	return reg
 }
@@ -1,6 +1,8 @@
 package lapi
 
 import (
+	"time"
+
 	"github.com/goline/errors"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -187,6 +189,72 @@ var _ = Describe("FactoryRoute", func() {
 		Expect(len(req.params.All())).To(BeZero())
 	})
 
+	It("Match should convert typed placeholders", func() {
+		req := &FactoryRequest{params: NewBag()}
+		req.WithUri("/users/-15/posts/hello-world/2020-01-02")
+		r := NewRoute("", "/users/<id:int>/posts/<slug:slug>/<day:date>", nil)
+		_, ok := r.Match(req)
+		Expect(ok).To(BeTrue())
+		Expect(req.params.All()).To(Equal(map[string]interface{}{
+			"id":   int64(-15),
+			"slug": "hello-world",
+			"day":  time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		}))
+
+		req.WithUri("/users/15/posts/Hello/2020-01-02")
+		_, ok = r.Match(req)
+		Expect(ok).To(BeFalse())
+
+		req.WithUri("/users/15/posts/hello/2020-13-45")
+		_, ok = r.Match(req)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("URL should format typed placeholders", func() {
+		r := NewRoute("GET", "/users/<uid:uuid>/<day:date>", nil)
+		u, err := r.URL(map[string]interface{}{
+			"uid": "123e4567-e89b-12d3-a456-426614174000",
+			"day": time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		})
+		Expect(err).To(BeNil())
+		Expect(u).To(Equal("/users/123e4567-e89b-12d3-a456-426614174000/2020-01-02"))
+	})
+
+	It("URL should fill placeholders and add query string", func() {
+		r := NewRoute("GET", "/users/<id:\\d+>/posts/<slug:[a-z-]+>", nil)
+		u, err := r.URL(map[string]interface{}{"id": 15, "slug": "hello-world", "page": 2})
+		Expect(err).To(BeNil())
+		Expect(u).To(Equal("/users/15/posts/hello-world?page=2"))
+	})
+
+	It("URL should fill host placeholders", func() {
+		r := NewRoute("GET", "/users/<id:\\d+>", nil).WithHost("<locale:[a-z]{2}>.domain.com")
+		u, err := r.URL(map[string]interface{}{"id": 15, "locale": "en"})
+		Expect(err).To(BeNil())
+		Expect(u).To(Equal("//en.domain.com/users/15"))
+	})
+
+	It("URL should return error code ERR_ROUTE_MISSING_PARAMETER", func() {
+		r := NewRoute("GET", "/users/<id:\\d+>", nil)
+		_, err := r.URL(nil)
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_ROUTE_MISSING_PARAMETER))
+	})
+
+	It("URL should return error code ERR_ROUTE_INVALID_PARAMETER", func() {
+		r := NewRoute("GET", "/users/<id:\\d+>", nil)
+		_, err := r.URL(map[string]interface{}{"id": "abc"})
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_ROUTE_INVALID_PARAMETER))
+	})
+
+	It("URL should return error code ERR_ROUTE_NOT_REVERSIBLE", func() {
+		r := NewRoute("GET", "/users/\\d+", nil)
+		_, err := r.URL(nil)
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_ROUTE_NOT_REVERSIBLE))
+	})
+
 	It("Tags should return route's tags", func() {
 		r := &FactoryRoute{tags: make([]string, 0)}
 		Expect(len(r.tags)).To(BeZero())
@@ -3,6 +3,11 @@ package lapi
 import (
 	"fmt"
 	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/goline/errors"
 )
@@ -42,6 +47,10 @@ type RouteInformer interface {
 
 	// Options registers an OPTION route handler
 	Options(uri string, handler Handler) Route
+
+	// WithAutoInform lets router answer HEAD requests with GET routes
+	// and OPTIONS requests with methods registered for uri
+	WithAutoInform(enabled bool) Router
 }
 
 // RouteRegister lets manually register a route
@@ -54,6 +63,10 @@ type RouteRegister interface {
 
 	// WithRoute registers a route
 	WithRoute(route Route) Router
+
+	// WithParamType registers a type of placeholder, such as <code:country> for name "country".
+	// It applies to routes registered afterwards, types registered on a group are shared with its parent
+	WithParamType(name string, paramType *ParamType) Router
 }
 
 // RouteGrouper groups sub routes
@@ -61,17 +74,24 @@ type RouteGrouper interface {
 	// Group collects a number of routes
 	Group(prefix string) Router
 
-	// WithHook allows to add hook to all router's routes
+	// WithHook allows to add hook to all router's routes, and routes answering OPTIONS automatically
 	WithHook(hook Hook) Router
 
 	// WithTag adds a tag to all routes
 	WithTag(tag string) Router
+
+	// WithSequentialHooks lets hooks of all routes run one after another
+	WithSequentialHooks(sequential bool) Router
 }
 
 // RouteMatcher matches request to route
 type RouteDispatcher interface {
-	// Route performs routing
+	// Route performs routing. Unlike Route.Match, uri of FactoryRoute is matched from its beginning,
+	// so /test does not match /x/test
 	Route(request Request) errors.Error
+
+	// Methods returns all HTTP methods registered for request's uri
+	Methods(request Request) []string
 }
 
 // RouteManager manages inner routes
@@ -87,6 +107,9 @@ type RouteManager interface {
 
 	// Remove deletes a route by name
 	Remove(name string) Router
+
+	// URL builds url of a route by name, see RouteBuilder
+	URL(name string, params map[string]interface{}) (string, errors.Error)
 }
 
 type RouteCopier interface {
@@ -110,9 +133,20 @@ func NewGroupRouter(parent Router, prefix string) Router {
 }
 
 type FactoryRouter struct {
-	routes []Route
-	parent Router
-	prefix string
+	routes     []Route
+	parent     Router
+	prefix     string
+	autoInform bool
+	types      map[string]*ParamType
+	hooks      []Hook
+	sequential bool
+	groups     []*FactoryRouter
+
+	// tree holds *routeTree which is built on dispatch, it is rebuilt if stale is set
+	// by changing routes via router, or if uri of a route is changed
+	tree   atomic.Value
+	stale  int32
+	treeMu sync.Mutex
 }
 
 func (r *FactoryRouter) Any(uri string, handler Handler) Route {
@@ -147,34 +181,63 @@ func (r *FactoryRouter) Options(uri string, handler Handler) Route {
 	return r.Register(http.MethodOptions, uri, handler)
 }
 
+func (r *FactoryRouter) WithAutoInform(enabled bool) Router {
+	r.autoInform = enabled
+	return r
+}
+
 func (r *FactoryRouter) Register(method string, uri string, handler Handler) Route {
 	if r.parent != nil && r.prefix != "" {
 		uri = fmt.Sprintf("%s%s", r.prefix, uri)
 		route := r.parent.Register(method, uri, handler)
 		r.routes = append(r.routes, route)
+		r.invalidateTree()
 		return route
 	} else {
-		route := NewRoute(method, uri, handler)
+		types := make(map[string]*ParamType, len(r.types))
+		for name, t := range r.types {
+			types[name] = t
+		}
+
+		route := newRoute(method, uri, handler, types)
 		_, ok := r.ByName(route.Name())
 		if ok == true {
 			panic(errors.New(ERR_ROUTER_DUPLICATE_ROUTE_NAME, fmt.Sprintf("Route with name %s has already been defined", route.Name())))
 		}
 
 		r.routes = append(r.routes, route)
+		r.invalidateTree()
 		return route
 	}
 }
 
 func (r *FactoryRouter) WithRoute(route Route) Router {
 	r.routes = append(r.routes, route)
+	r.invalidateTree()
+	return r
+}
+
+func (r *FactoryRouter) WithParamType(name string, paramType *ParamType) Router {
+	if r.parent != nil {
+		r.parent.WithParamType(name, paramType)
+		return r
+	}
+
+	if r.types == nil {
+		r.types = make(map[string]*ParamType)
+	}
+	r.types[name] = paramType
 	return r
 }
 
 func (r *FactoryRouter) Group(prefix string) Router {
-	return NewGroupRouter(r, prefix)
+	group := NewGroupRouter(r, prefix)
+	r.groups = append(r.groups, group.(*FactoryRouter))
+	return group
 }
 
 func (r *FactoryRouter) WithHook(hook Hook) Router {
+	r.hooks = append(r.hooks, hook)
 	for _, route := range r.routes {
 		route.WithHook(hook)
 	}
@@ -190,6 +253,14 @@ func (r *FactoryRouter) WithTag(tag string) Router {
 	return r
 }
 
+func (r *FactoryRouter) WithSequentialHooks(sequential bool) Router {
+	r.sequential = sequential
+	for _, route := range r.routes {
+		route.WithSequentialHooks(sequential)
+	}
+	return r
+}
+
 func (r *FactoryRouter) ByName(name string) (Route, bool) {
 	for _, route := range r.routes {
 		if route.Name() == name {
@@ -208,6 +279,7 @@ func (r *FactoryRouter) Set(name string, route Route) Router {
 	if ok == true {
 		route.WithName(name)
 		r.routes[i] = route
+		r.invalidateTree()
 	}
 	return r
 }
@@ -216,25 +288,68 @@ func (r *FactoryRouter) Remove(name string) Router {
 	i, ok := r.routeIndex(name)
 	if ok == true {
 		r.routes = append(r.routes[:i], r.routes[i+1:]...)
+		r.invalidateTree()
 	}
 	return r
 }
 
+func (r *FactoryRouter) URL(name string, params map[string]interface{}) (string, errors.Error) {
+	route, ok := r.ByName(name)
+	if ok == false {
+		return "", errors.New(ERR_ROUTE_NOT_FOUND, fmt.Sprintf("Route with name %s could not be found", name))
+	}
+
+	return route.URL(params)
+}
+
 func (r *FactoryRouter) Route(request Request) errors.Error {
-	for _, route := range r.routes {
-		if matchedRoute, ok := route.Match(request); ok == true {
+	if matchedRoute, ok := r.routeTree().Match(request); ok == true {
+		request.WithRoute(matchedRoute)
+		return nil
+	}
+
+	if r.autoInform == true {
+		if matchedRoute, ok := r.inform(request); ok == true {
 			request.WithRoute(matchedRoute)
 			return nil
 		}
 	}
+
+	if len(r.Methods(request)) > 0 {
+		return errors.New(ERR_HTTP_METHOD_NOT_ALLOWED, fmt.Sprintf("Method %s is not allowed for url %s", request.Method(), request.Uri())).
+			WithLevel(errors.LEVEL_WARN)
+	}
 	return errors.New(ERR_HTTP_NOT_FOUND, fmt.Sprintf("Url (%s %s) could not be found", request.Method(), request.Uri())).
 		WithLevel(errors.LEVEL_WARN)
 }
 
+func (r *FactoryRouter) Methods(request Request) []string {
+	set := make(map[string]bool)
+	for _, route := range r.routeTree().All(request) {
+		if route.Method() != "" {
+			set[route.Method()] = true
+		}
+	}
+	if r.autoInform == true && len(set) > 0 {
+		if set[http.MethodGet] == true {
+			set[http.MethodHead] = true
+		}
+		set[http.MethodOptions] = true
+	}
+
+	methods := make([]string, 0, len(set))
+	for method := range set {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
 func (r *FactoryRouter) Copy(router Router) Router {
 	for _, route := range router.Routes() {
 		r.routes = append(r.routes, route)
 	}
+	r.invalidateTree()
 	return r
 }
 
@@ -246,3 +361,95 @@ func (r *FactoryRouter) routeIndex(name string) (int, bool) {
 	}
 	return -1, false
 }
+
+// inform finds GET route for HEAD request,
+// or builds a route answering OPTIONS request from the route table
+func (r *FactoryRouter) inform(request Request) (Route, bool) {
+	switch request.Method() {
+	case http.MethodHead:
+		request.WithMethod(http.MethodGet)
+		defer request.WithMethod(http.MethodHead)
+		return r.routeTree().Match(request)
+	case http.MethodOptions:
+		routes := r.routeTree().All(request)
+		if len(routes) == 0 {
+			return nil, false
+		}
+
+		// hooks of router and groups owning routes are used, so CORS-like hooks still run
+		// while hooks of a specific route do not
+		uri := "^" + regexp.QuoteMeta(request.Uri()) + "$"
+		route := &FactoryRoute{
+			name:       fmt.Sprintf("%s_%s", http.MethodOptions, strings.Replace(request.Uri(), "/", "_", -1)),
+			method:     http.MethodOptions,
+			uri:        request.Uri(),
+			handler:    &informHandler{r.Methods(request)},
+			tags:       make([]string, 0),
+			sequential: r.sequential,
+			pvHost:     &patternVerifier{},
+			pvUri:      &patternVerifier{pattern: uri, reg: regexp.MustCompile(uri)},
+		}
+		if host := routes[0].Host(); host != "" {
+			route.WithHost(host)
+		}
+		route.WithHooks(r.informHooks(routes)...)
+		return route, true
+	default:
+		return nil, false
+	}
+}
+
+// informHooks returns hooks of router, and hooks of its groups which own any of routes
+func (r *FactoryRouter) informHooks(routes []Route) []Hook {
+	hooks := append([]Hook{}, r.hooks...)
+	for _, group := range r.groups {
+		for _, route := range routes {
+			if _, ok := group.routeIndex(route.Name()); ok == true {
+				hooks = append(hooks, group.informHooks(routes)...)
+				break
+			}
+		}
+	}
+	return hooks
+}
+
+// routeTree returns the current tree without locking, it is rebuilt if routes are changed
+func (r *FactoryRouter) routeTree() *routeTree {
+	if t, ok := r.currentTree(); ok == true {
+		return t
+	}
+
+	r.treeMu.Lock()
+	defer r.treeMu.Unlock()
+	if t, ok := r.currentTree(); ok == true {
+		return t
+	}
+
+	atomic.StoreInt32(&r.stale, 0)
+	t := newRouteTree(r.routes)
+	r.tree.Store(t)
+	return t
+}
+
+func (r *FactoryRouter) currentTree() (*routeTree, bool) {
+	t, ok := r.tree.Load().(*routeTree)
+	if ok == false || atomic.LoadInt32(&r.stale) == 1 || t.revision != atomic.LoadUint64(&routeRevision) {
+		return nil, false
+	}
+	return t, true
+}
+
+func (r *FactoryRouter) invalidateTree() {
+	atomic.StoreInt32(&r.stale, 1)
+}
+
+// informHandler answers OPTIONS request with allowed methods
+type informHandler struct {
+	methods []string
+}
+
+func (h *informHandler) Handle(c Connection) (interface{}, errors.Error) {
+	c.Response().Header().Set(HEADER_ALLOW, strings.Join(h.methods, ", "))
+	c.Response().WithStatus(http.StatusNoContent)
+	return nil, nil
+}
@@ -2,6 +2,7 @@ package lapi
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/goline/errors"
 	. "github.com/onsi/ginkgo"
@@ -170,12 +171,107 @@ var _ = Describe("FactoryRouter", func() {
 		r := &FactoryRouter{routes: make([]Route, 0)}
 		r.Get("/test", nil).WithName("Get_Test")
 		req := NewRequest(nil)
-		req.WithMethod(http.MethodPost).WithUri("/test")
+		req.WithMethod(http.MethodGet).WithUri("/test/2")
 		err := r.Route(req)
 		Expect(err).NotTo(BeNil())
 		Expect(err.Code()).To(Equal(ERR_HTTP_NOT_FOUND))
 	})
 
+	It("Route should return error code ERR_HTTP_METHOD_NOT_ALLOWED", func() {
+		r := &FactoryRouter{routes: make([]Route, 0)}
+		r.Get("/test/<id:\\d+>", nil).WithName("Get_Test")
+		r.Put("/test/<id:\\d+>", nil).WithName("Put_Test")
+		r.Delete("/test/<path:.+>", nil).WithName("Delete_Test")
+		req := NewRequest(nil)
+		req.WithMethod(http.MethodPost).WithUri("/test/1")
+		err := r.Route(req)
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_HTTP_METHOD_NOT_ALLOWED))
+		Expect(r.Methods(req)).To(Equal([]string{"DELETE", "GET", "PUT"}))
+	})
+
+	It("Route should route HEAD request to GET route when auto inform is enabled", func() {
+		r := &FactoryRouter{routes: make([]Route, 0)}
+		r.Get("/test", nil).WithName("Get_Test")
+		req := NewRequest(nil)
+		req.WithMethod(http.MethodHead).WithUri("/test")
+		Expect(r.Route(req)).NotTo(BeNil())
+
+		r.WithAutoInform(true)
+		Expect(r.Route(req)).To(BeNil())
+		Expect(req.Route().Name()).To(Equal("Get_Test"))
+		Expect(req.Method()).To(Equal(http.MethodHead))
+	})
+
+	It("Route should answer OPTIONS request when auto inform is enabled", func() {
+		r := &FactoryRouter{routes: make([]Route, 0)}
+		r.Get("/test", nil).WithHook(&routeHook{})
+		r.WithHook(&routeHook{})
+		r.Post("/test", nil)
+		r.WithAutoInform(true)
+		req := NewRequest(nil)
+		req.WithMethod(http.MethodOptions).WithUri("/test")
+		Expect(r.Route(req)).To(BeNil())
+		Expect(req.Route().Method()).To(Equal(http.MethodOptions))
+		Expect(len(Sorted(req.Route().Hooks()))).To(Equal(1))
+
+		c := NewConnection(req, &FactoryResponse{header: NewHeader(), body: NewBody(nil, nil)})
+		_, err := req.Route().Handler().Handle(c)
+		Expect(err).To(BeNil())
+		Expect(c.Response().Status()).To(Equal(http.StatusNoContent))
+		allow, _ := c.Response().Header().Get(HEADER_ALLOW)
+		Expect(allow).To(Equal("GET, HEAD, OPTIONS, POST"))
+	})
+
+	It("Route should answer OPTIONS request with hooks of groups owning routes", func() {
+		r := NewRouter()
+		r.WithAutoInform(true)
+		api := r.Group("/api")
+		api.Get("/test", nil).WithHost("example.com")
+		api.WithHook(&routeHook{})
+		r.Get("/other", nil)
+
+		req := NewRequest(nil)
+		req.WithMethod(http.MethodOptions).WithHost("example.com").WithUri("/api/test")
+		Expect(r.Route(req)).To(BeNil())
+		Expect(len(Sorted(req.Route().Hooks()))).To(Equal(1))
+		_, ok := req.Route().Match(req)
+		Expect(ok).To(BeTrue())
+
+		req.WithUri("/other")
+		Expect(r.Route(req)).To(BeNil())
+		Expect(len(Sorted(req.Route().Hooks()))).To(Equal(0))
+	})
+
+	It("WithParamType should register a custom type of placeholder", func() {
+		r := NewRouter()
+		r.Group("/v1").WithParamType("country", &ParamType{
+			Pattern: "[a-z]{2}",
+			Convert: func(value string) (interface{}, error) { return strings.ToUpper(value), nil },
+		})
+		r.Get("/countries/<code:country>", nil)
+		req := NewRequest(nil)
+		req.WithMethod(http.MethodGet).WithUri("/countries/vn")
+		Expect(r.Route(req)).To(BeNil())
+		code, _ := req.Param("code")
+		Expect(code).To(Equal("VN"))
+
+		req.WithUri("/countries/vnm")
+		Expect(r.Route(req)).NotTo(BeNil())
+	})
+
+	It("URL should build url of a route by name", func() {
+		r := NewRouter()
+		r.Get("/users/<id:\\d+>", nil).WithName("user")
+		u, err := r.URL("user", map[string]interface{}{"id": 1})
+		Expect(err).To(BeNil())
+		Expect(u).To(Equal("/users/1"))
+
+		_, err = r.URL("unknown", nil)
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_ROUTE_NOT_FOUND))
+	})
+
 	It("WithHook should register hook for all routes", func() {
 		r := &FactoryRouter{routes: make([]Route, 0)}
 		r.Register("GET", "/test", nil).WithName("my_route")
@@ -0,0 +1,149 @@
+package lapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goline/errors"
+)
+
+// EventStream sends Server-Sent Events
+type EventStream interface {
+	// Send writes an event, it returns ERR_STREAM_CLOSED when client is gone
+	Send(event *Event) errors.Error
+
+	// WithHeartbeat sends a comment every interval to keep connection alive until stream is done
+	WithHeartbeat(interval time.Duration) EventStream
+
+	// LastEventId returns Last-Event-ID header of reconnecting client, so it could resume events
+	LastEventId() string
+
+	// Done returns a channel which is closed when stream is closed or client disconnects
+	Done() <-chan struct{}
+
+	// Close stops stream and its heartbeat
+	Close()
+}
+
+// Event is a Server-Sent Event
+type Event struct {
+	// Id is sent back as Last-Event-ID when client reconnects
+	Id string
+
+	// Name is type of event, client receives "message" if it is empty
+	Name string
+
+	// Data is written as is if it is string or []byte, otherwise it is encoded as JSON
+	Data interface{}
+
+	// Retry hints client how long to wait before reconnecting
+	Retry time.Duration
+}
+
+// NewEventStream starts a text/event-stream response of connection.
+// Stream is done when request's context is done, that is client disconnects, or handler returns
+func NewEventStream(c Connection) (EventStream, errors.Error) {
+	c.Response().Header().Set(HEADER_CACHE, "no-cache")
+	c.Response().Body().WithContentType(CONTENT_TYPE_EVENTS).WithCharset(CONTENT_CHARSET_DEFAULT)
+
+	ctx := context.Background()
+	if r := c.Request().Ancestor(); r != nil {
+		ctx = r.Context()
+	}
+
+	stream, err := c.Response().Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lastEventId, _ := c.Request().Header().Get(HEADER_LAST_EVENT)
+	return &FactoryEventStream{stream: stream, parser: new(JsonParser), lastEventId: lastEventId}, nil
+}
+
+type FactoryEventStream struct {
+	stream      Stream
+	parser      Parser
+	lastEventId string
+	heartbeat   sync.Once
+}
+
+func (s *FactoryEventStream) Send(event *Event) errors.Error {
+	var buf bytes.Buffer
+	if event.Id != "" {
+		fmt.Fprintf(&buf, "id: %s\n", s.clean(event.Id))
+	}
+	if event.Name != "" {
+		fmt.Fprintf(&buf, "event: %s\n", s.clean(event.Name))
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(&buf, "retry: %d\n", event.Retry/time.Millisecond)
+	}
+
+	var data string
+	switch v := event.Data.(type) {
+	case nil:
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		b, err := s.parser.Encode(v)
+		if err != nil {
+			return errors.New(ERR_PARSE_ENCODE_FAILURE, "Unable to encode event's data").WithDebug(err.Error())
+		}
+		data = string(b)
+	}
+	if event.Data != nil {
+		for _, line := range strings.Split(strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(data), "\n") {
+			fmt.Fprintf(&buf, "data: %s\n", line)
+		}
+	}
+	buf.WriteString("\n")
+
+	return s.stream.Send(buf.Bytes())
+}
+
+func (s *FactoryEventStream) WithHeartbeat(interval time.Duration) EventStream {
+	if interval <= 0 {
+		return s
+	}
+
+	s.heartbeat.Do(func() {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-s.stream.Done():
+					return
+				case <-ticker.C:
+					if s.stream.Send(":\n\n") != nil {
+						return
+					}
+				}
+			}
+		}()
+	})
+	return s
+}
+
+func (s *FactoryEventStream) LastEventId() string {
+	return s.lastEventId
+}
+
+func (s *FactoryEventStream) Done() <-chan struct{} {
+	return s.stream.Done()
+}
+
+func (s *FactoryEventStream) Close() {
+	s.stream.Close()
+}
+
+// clean removes line breaks which would break event's fields
+func (s *FactoryEventStream) clean(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
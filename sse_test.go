@@ -0,0 +1,100 @@
+package lapi
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goline/errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// sseRecorder is a flushable writer which could be read while heartbeat is writing
+type sseRecorder struct {
+	mu     sync.Mutex
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (w *sseRecorder) Header() http.Header { return w.header }
+func (w *sseRecorder) WriteHeader(int)     {}
+func (w *sseRecorder) Flush()              {}
+func (w *sseRecorder) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.body.Write(b)
+}
+func (w *sseRecorder) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.body.String()
+}
+
+var _ = Describe("FactoryEventStream", func() {
+	It("Send should write events", func() {
+		req := httptest.NewRequest("GET", "/events", nil)
+		req.Header.Set("Last-Event-ID", "41")
+		rw := httptest.NewRecorder()
+		s, err := NewEventStream(NewConnection(NewRequest(req), NewResponse(rw)))
+		Expect(err).To(BeNil())
+		Expect(s.LastEventId()).To(Equal("41"))
+		Expect(rw.Header().Get("Content-Type")).To(Equal("text/event-stream; charset=utf-8"))
+		Expect(rw.Header().Get("Cache-Control")).To(Equal("no-cache"))
+
+		Expect(s.Send(&Event{Id: "42", Name: "update", Data: map[string]int{"a": 1}, Retry: 3 * time.Second})).To(BeNil())
+		Expect(s.Send(&Event{Data: "line 1\nline 2"})).To(BeNil())
+		Expect(s.Send(&Event{Data: "a\rb\r\nc"})).To(BeNil())
+		Expect(rw.Body.String()).To(Equal("id: 42\nevent: update\nretry: 3000\ndata: {\"a\":1}\n\n" +
+			"data: line 1\ndata: line 2\n\n" +
+			"data: a\ndata: b\ndata: c\n\n"))
+	})
+
+	It("should send heartbeats until client disconnects", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		req := httptest.NewRequest("GET", "/events", nil).WithContext(ctx)
+		rw := &sseRecorder{header: http.Header{}}
+		s, err := NewEventStream(NewConnection(NewRequest(req), NewResponse(rw)))
+		Expect(err).To(BeNil())
+		s.WithHeartbeat(time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		Expect(strings.HasPrefix(rw.String(), ":\n\n")).To(BeTrue())
+
+		cancel()
+		Expect(s.Done()).To(BeClosed())
+		Expect(s.Send(&Event{Data: "x"}).Code()).To(Equal(ERR_STREAM_CLOSED))
+	})
+
+	It("should stop heartbeat when handler returns", func() {
+		handler := &sseHandler{}
+		app := NewApp()
+		app.Router().Get("/events", handler)
+		app.Run()
+
+		rw := &sseRecorder{header: http.Header{}}
+		app.ServeHTTP(rw, httptest.NewRequest("GET", "/events", nil))
+		Expect(handler.stream.Done()).To(BeClosed())
+
+		sent := rw.String()
+		time.Sleep(10 * time.Millisecond)
+		Expect(rw.String()).To(Equal(sent))
+	})
+})
+
+type sseHandler struct {
+	stream EventStream
+}
+
+func (h *sseHandler) Handle(c Connection) (interface{}, errors.Error) {
+	stream, err := NewEventStream(c)
+	if err != nil {
+		return nil, err
+	}
+	h.stream = stream.WithHeartbeat(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	return nil, nil
+}
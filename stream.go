@@ -0,0 +1,111 @@
+package lapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/goline/errors"
+)
+
+// Stream writes chunks of response to client, every chunk is flushed immediately.
+// It is safe to be used by multiple goroutines
+type Stream interface {
+	// Write writes and flushes a chunk, it returns ERR_STREAM_CLOSED when stream is done
+	io.Writer
+
+	// Send writes output as a chunk. String and []byte are written as is,
+	// other values are encoded by parser of response's content-type
+	Send(output interface{}) errors.Error
+
+	// Done returns a channel which is closed when stream is closed or client disconnects
+	Done() <-chan struct{}
+
+	// Close stops stream, it waits for a write in progress, following writes are rejected
+	Close()
+}
+
+func newStream(ctx context.Context, w io.Writer, flusher http.Flusher, parsers ParserManager, contentType string) Stream {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	return &FactoryStream{
+		writer:      w,
+		flusher:     flusher,
+		parsers:     parsers,
+		contentType: contentType,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+type FactoryStream struct {
+	mu          sync.Mutex
+	writer      io.Writer
+	flusher     http.Flusher
+	parsers     ParserManager
+	contentType string
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+func (s *FactoryStream) Write(data []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx.Err() != nil {
+		return 0, errors.New(ERR_STREAM_CLOSED, "Stream is closed")
+	}
+
+	n, err := s.writer.Write(data)
+	if err != nil {
+		s.cancel()
+		return n, errors.New(ERR_BODY_WRITER_FAILURE, "Unable to write output").WithDebug(err.Error())
+	}
+	s.flusher.Flush()
+	return n, nil
+}
+
+func (s *FactoryStream) Send(output interface{}) errors.Error {
+	var data []byte
+	switch v := output.(type) {
+	case nil:
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		p, ok := s.parsers.Parser(s.contentType)
+		if ok == false {
+			return errors.New(ERR_NO_PARSER_FOUND, fmt.Sprintf("Unable to find an appropriate parser for %s", s.contentType))
+		}
+
+		bytes, err := p.Encode(output)
+		if err != nil {
+			return errors.New(ERR_PARSE_ENCODE_FAILURE, fmt.Sprintf("Unable to encode %s", reflect.TypeOf(output))).WithDebug(err.Error())
+		}
+		data = bytes
+	}
+
+	if _, err := s.Write(data); err != nil {
+		return err.(errors.Error)
+	}
+	return nil
+}
+
+func (s *FactoryStream) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+func (s *FactoryStream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cancel()
+}
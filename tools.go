@@ -47,13 +47,32 @@ func StructOf(v interface{}) reflect.Type {
 }
 
 func Parallel(list map[int]*Slice, f SliceFunc) {
+	for _, i := range sortedIndexes(list) {
+		list[i].Run(f)
+	}
+}
+
+// Sequential runs f on items one after another, in priority order
+func Sequential(list map[int]*Slice, f SliceFunc) {
+	for _, item := range Sorted(list) {
+		f(item)
+	}
+}
+
+// Sorted returns all items of list in priority order, then in order of appending
+func Sorted(list map[int]*Slice) []interface{} {
+	items := make([]interface{}, 0)
+	for _, i := range sortedIndexes(list) {
+		items = append(items, list[i].All()...)
+	}
+	return items
+}
+
+func sortedIndexes(list map[int]*Slice) []int {
 	indexes := make(sort.IntSlice, 0)
 	for i := range list {
 		indexes = append(indexes, i)
 	}
 	sort.Sort(indexes)
-
-	for _, i := range indexes {
-		list[i].Run(f)
-	}
+	return indexes
 }
@@ -40,4 +40,11 @@ var _ = Describe("Utils", func() {
 		})
 		Expect(len(s.All())).To(Equal(7))
 	})
+
+	It("Sorted should return items by priority", func() {
+		m := make(map[int]*Slice)
+		m[5] = new(Slice).Append("c")
+		m[1] = new(Slice).Append("a").Append("b")
+		Expect(Sorted(m)).To(Equal([]interface{}{"a", "b", "c"}))
+	})
 })
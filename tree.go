@@ -0,0 +1,407 @@
+package lapi
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// routeTree is a compressed prefix tree which dispatches requests to routes.
+// Static parts of uri are stored as radix edges, placeholders become parameter
+// nodes which only test a single path segment. Routes which could not be
+// described as a tree (raw regular expressions, placeholders spanning over "/",
+// custom Route implementations) are kept in fallback and matched one by one.
+// Uri of FactoryRoute is anchored at both ends, in the tree and in fallback alike.
+type routeTree struct {
+	root     *routeNode
+	fallback []routeEntry
+
+	// revision is routeRevision which tree is built from
+	revision uint64
+}
+
+type routeEntry struct {
+	index int
+	route Route
+}
+
+type routeNode struct {
+	prefix   string
+	param    *routeParam
+	statics  []*routeNode
+	params   []*routeNode
+	leaves   []routeEntry
+	minIndex int
+}
+
+type routeParam struct {
+	key       string
+	pattern   string
+	paramType *ParamType
+	match     func(segment string) bool
+}
+
+type routePart struct {
+	static string
+	param  *routeParam
+}
+
+type routeMatch struct {
+	entry  routeEntry
+	found  bool
+	params []*routeParam
+	values []string
+
+	// all collects every route matching host and uri regardless of method
+	all     bool
+	entries []routeEntry
+}
+
+// routeKeyRegexp detects placeholders in form of <name:regex>
+var routeKeyRegexp = regexp.MustCompile(`(\<(\w+):([^\>]+)\>)`)
+
+func newRouteTree(routes []Route) *routeTree {
+	t := &routeTree{root: &routeNode{minIndex: len(routes)}, revision: atomic.LoadUint64(&routeRevision)}
+	for i, route := range routes {
+		entry := routeEntry{i, route}
+		r, ok := route.(*FactoryRoute)
+		if ok == false {
+			t.fallback = append(t.fallback, entry)
+			continue
+		}
+
+		parts, ok := splitRouteUri(r)
+		if ok == false {
+			t.fallback = append(t.fallback, entry)
+			continue
+		}
+
+		t.root.insert(parts, entry)
+	}
+	return t
+}
+
+// Match looks up the earliest registered route which matches request,
+// as scanning all routes in order would do with anchored uri
+func (t *routeTree) Match(request Request) (Route, bool) {
+	m := &routeMatch{entry: routeEntry{index: -1}}
+	t.root.lookup(request, request.Uri(), m, nil, nil)
+
+	for _, entry := range t.fallback {
+		if m.found == true && entry.index > m.entry.index {
+			break
+		}
+
+		if route, ok := matchFallback(entry.route, request); ok == true {
+			return route, true
+		}
+	}
+
+	if m.found == false {
+		return nil, false
+	}
+
+	route := m.entry.route.(*FactoryRoute)
+	route.modifyRequestOnMatch(request, route.pvHost, request.Host())
+	for i, param := range m.params {
+		value, _ := param.paramType.convert(m.values[i])
+		request.WithParam(param.key, value)
+	}
+	return route, true
+}
+
+// All returns routes which match request's host and uri regardless of method,
+// in order of registration
+func (t *routeTree) All(request Request) []Route {
+	m := &routeMatch{entry: routeEntry{index: -1}, all: true}
+	t.root.lookup(request, request.Uri(), m, nil, nil)
+
+	for _, entry := range t.fallback {
+		r, ok := entry.route.(*FactoryRoute)
+		if ok == true && r.matchHost(request.Host()) && r.matchUri(request.Uri()) && r.pvUri.matchStart(request.Uri()) {
+			m.entries = append(m.entries, entry)
+		}
+	}
+
+	sort.Slice(m.entries, func(i, j int) bool {
+		return m.entries[i].index < m.entries[j].index
+	})
+	routes := make([]Route, len(m.entries))
+	for i, entry := range m.entries {
+		routes[i] = entry.route
+	}
+	return routes
+}
+
+// matchFallback matches route as Route.Match does, but uri of FactoryRoute must match from its beginning
+func matchFallback(route Route, request Request) (Route, bool) {
+	r, ok := route.(*FactoryRoute)
+	if ok == false {
+		return route.Match(request)
+	}
+
+	if r.pvUri.matchStart(request.Uri()) == false {
+		return nil, false
+	}
+	return r.Match(request)
+}
+
+func (n *routeNode) insert(parts []routePart, entry routeEntry) {
+	if entry.index < n.minIndex {
+		n.minIndex = entry.index
+	}
+
+	if len(parts) == 0 {
+		n.leaves = append(n.leaves, entry)
+		return
+	}
+
+	part := parts[0]
+	if part.param != nil {
+		n.paramChild(part.param, entry.index).insert(parts[1:], entry)
+		return
+	}
+
+	n.insertStatic(part.static, parts[1:], entry)
+}
+
+func (n *routeNode) insertStatic(s string, parts []routePart, entry routeEntry) {
+	if s == "" {
+		n.insert(parts, entry)
+		return
+	}
+
+	for _, child := range n.statics {
+		l := commonPrefixLength(s, child.prefix)
+		if l == 0 {
+			continue
+		}
+
+		if l < len(child.prefix) {
+			child.split(l)
+		}
+		if entry.index < child.minIndex {
+			child.minIndex = entry.index
+		}
+		child.insertStatic(s[l:], parts, entry)
+		return
+	}
+
+	child := &routeNode{prefix: s, minIndex: entry.index}
+	n.statics = append(n.statics, child)
+	child.insert(parts, entry)
+}
+
+// split cuts node's prefix at position l, moving its content to a new child
+func (n *routeNode) split(l int) {
+	child := &routeNode{
+		prefix:   n.prefix[l:],
+		statics:  n.statics,
+		params:   n.params,
+		leaves:   n.leaves,
+		minIndex: n.minIndex,
+	}
+	n.prefix = n.prefix[:l]
+	n.statics = []*routeNode{child}
+	n.params = nil
+	n.leaves = nil
+}
+
+func (n *routeNode) paramChild(param *routeParam, index int) *routeNode {
+	for _, child := range n.params {
+		if child.param.key == param.key && child.param.pattern == param.pattern && child.param.paramType == param.paramType {
+			return child
+		}
+	}
+
+	child := &routeNode{param: param, minIndex: index}
+	n.params = append(n.params, child)
+	return child
+}
+
+func (n *routeNode) lookup(request Request, path string, m *routeMatch, params []*routeParam, values []string) {
+	if path == "" {
+		for _, entry := range n.leaves {
+			if m.found == true && entry.index > m.entry.index {
+				break
+			}
+
+			route := entry.route.(*FactoryRoute)
+			if m.all == true {
+				if route.matchHost(request.Host()) {
+					m.entries = append(m.entries, entry)
+				}
+				continue
+			}
+
+			if route.matchMethod(request.Method()) && route.matchHost(request.Host()) {
+				m.entry = entry
+				m.found = true
+				m.params = append(m.params[:0], params...)
+				m.values = append(m.values[:0], values...)
+				break
+			}
+		}
+	}
+
+	for _, child := range n.statics {
+		if m.found == true && child.minIndex > m.entry.index {
+			continue
+		}
+
+		if strings.HasPrefix(path, child.prefix) {
+			child.lookup(request, path[len(child.prefix):], m, params, values)
+		}
+	}
+
+	if len(n.params) == 0 {
+		return
+	}
+
+	end := strings.IndexByte(path, '/')
+	if end < 0 {
+		end = len(path)
+	}
+	segment := path[:end]
+	for _, child := range n.params {
+		if m.found == true && child.minIndex > m.entry.index {
+			continue
+		}
+
+		if child.param.match(segment) {
+			child.lookup(request, path[end:], m, append(params, child.param), append(values, segment))
+		}
+	}
+}
+
+// splitRouteUri breaks route's uri into static and parameter parts.
+// It returns false when uri could not be represented as a tree
+func splitRouteUri(r *FactoryRoute) ([]routePart, bool) {
+	if r.autoEnding == false || r.uri == "" {
+		return nil, false
+	}
+
+	uri := strings.TrimSuffix(strings.TrimPrefix(r.uri, "^"), "$")
+	return appendRouteParts(r, make([]routePart, 0), uri)
+}
+
+func appendRouteParts(r *FactoryRoute, parts []routePart, uri string) ([]routePart, bool) {
+	m := routeKeyRegexp.FindStringSubmatchIndex(uri)
+	if m == nil {
+		if isLiteralUri(uri) == false {
+			return nil, false
+		}
+		return append(parts, routePart{static: uri}), true
+	}
+
+	static := uri[:m[0]]
+	next := uri[m[1]:]
+	if isLiteralUri(static) == false || (next != "" && next[0] != '/') {
+		return nil, false
+	}
+
+	pattern, paramType := r.paramPattern(uri[m[6]:m[7]])
+	param, ok := newRouteParam(uri[m[4]:m[5]], pattern, paramType)
+	if ok == false {
+		return nil, false
+	}
+
+	return appendRouteParts(r, append(parts, routePart{static: static}, routePart{param: param}), next)
+}
+
+// isStaticUri checks whether s could be written into url as is.
+// Dot is accepted as uri often contains it, such as /v1.0/users or /data.json
+func isStaticUri(s string) bool {
+	return strings.ContainsAny(s, `\+*?()|[]{}^$`) == false
+}
+
+// isLiteralUri checks whether s matches only itself, so dots which mean any character are left to fallback
+func isLiteralUri(s string) bool {
+	return isStaticUri(s) == true && strings.Contains(s, ".") == false
+}
+
+func newRouteParam(key string, pattern string, paramType *ParamType) (*routeParam, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil || canMatchSlash(re) {
+		return nil, false
+	}
+
+	p := &routeParam{key: key, pattern: pattern, paramType: paramType}
+	switch pattern {
+	case `\d+`, `[0-9]+`, `-?\d+`:
+		signed := pattern == `-?\d+`
+		p.match = func(s string) bool {
+			if signed == true && strings.HasPrefix(s, "-") {
+				s = s[1:]
+			}
+			if s == "" {
+				return false
+			}
+			for i := 0; i < len(s); i++ {
+				if s[i] < '0' || s[i] > '9' {
+					return false
+				}
+			}
+			return true
+		}
+	case `[^/]+`:
+		p.match = func(s string) bool {
+			return s != ""
+		}
+	default:
+		reg, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, false
+		}
+		p.match = reg.MatchString
+	}
+
+	if paramType != nil && paramType.Convert != nil {
+		match := p.match
+		p.match = func(s string) bool {
+			if match(s) == false {
+				return false
+			}
+			_, err := paramType.Convert(s)
+			return err == nil
+		}
+	}
+	return p, true
+}
+
+// canMatchSlash reports whether regular expression could consume "/" character
+func canMatchSlash(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return true
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if r == '/' {
+				return true
+			}
+		}
+	case syntax.OpCharClass:
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			if re.Rune[i] <= '/' && '/' <= re.Rune[i+1] {
+				return true
+			}
+		}
+	}
+
+	for _, sub := range re.Sub {
+		if canMatchSlash(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func commonPrefixLength(a string, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
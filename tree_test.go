@@ -0,0 +1,235 @@
+package lapi
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("routeTree", func() {
+	It("Match should extract parameters like FactoryRoute.Match does", func() {
+		r := NewRouter()
+		r.Get("/users/<id:\\d+>/posts/<slug:[a-z-]+>", nil).WithName("post")
+		req := NewRequest(nil)
+		req.WithMethod(http.MethodGet).WithUri("/users/15/posts/hello-world")
+		route, ok := newRouteTree(r.Routes()).Match(req)
+		Expect(ok).To(BeTrue())
+		Expect(route.Name()).To(Equal("post"))
+
+		id, _ := req.Param("id")
+		Expect(id).To(Equal("15"))
+		slug, _ := req.Param("slug")
+		Expect(slug).To(Equal("hello-world"))
+	})
+
+	It("Match should convert typed placeholders", func() {
+		r := NewRouter()
+		r.Get("/users/<id:int>", nil).WithName("user")
+		r.Get("/users/<name:\\w+>", nil).WithName("by_name")
+		req := NewRequest(nil)
+		req.WithMethod(http.MethodGet).WithUri("/users/15")
+		route, ok := newRouteTree(r.Routes()).Match(req)
+		Expect(ok).To(BeTrue())
+		Expect(route.Name()).To(Equal("user"))
+		id, _ := req.Param("id")
+		Expect(id).To(Equal(int64(15)))
+
+		req.WithUri("/users/99999999999999999999")
+		route, ok = newRouteTree(r.Routes()).Match(req)
+		Expect(ok).To(BeTrue())
+		Expect(route.Name()).To(Equal("by_name"))
+	})
+
+	It("Match should prefer the earliest registered route", func() {
+		r := NewRouter()
+		r.Get("/users/<name:\\w+>", nil).WithName("by_name")
+		r.Get("/users/me", nil).WithName("me")
+		req := NewRequest(nil)
+		req.WithMethod(http.MethodGet).WithUri("/users/me")
+		route, ok := newRouteTree(r.Routes()).Match(req)
+		Expect(ok).To(BeTrue())
+		Expect(route.Name()).To(Equal("by_name"))
+	})
+
+	It("Match should split shared prefixes", func() {
+		r := NewRouter()
+		r.Get("/users", nil).WithName("users")
+		r.Get("/user/<id:\\d+>", nil).WithName("user")
+		r.Get("/uploads", nil).WithName("uploads")
+		t := newRouteTree(r.Routes())
+
+		for uri, name := range map[string]string{"/users": "users", "/user/1": "user", "/uploads": "uploads"} {
+			req := NewRequest(nil)
+			req.WithMethod(http.MethodGet).WithUri(uri)
+			route, ok := t.Match(req)
+			Expect(ok).To(BeTrue())
+			Expect(route.Name()).To(Equal(name))
+		}
+
+		req := NewRequest(nil)
+		req.WithMethod(http.MethodGet).WithUri("/user")
+		_, ok := t.Match(req)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("Match should skip routes with different method", func() {
+		r := NewRouter()
+		r.Get("/test/<id:\\d+>", nil).WithName("get")
+		r.Put("/test/<id:\\d+>", nil).WithName("put")
+		req := NewRequest(nil)
+		req.WithMethod(http.MethodPut).WithUri("/test/1")
+		route, ok := newRouteTree(r.Routes()).Match(req)
+		Expect(ok).To(BeTrue())
+		Expect(route.Name()).To(Equal("put"))
+	})
+
+	It("Match should verify host and set host parameters", func() {
+		r := NewRouter()
+		r.Get("/test", nil).WithHost("<locale:[a-z]{2}>.domain.com").WithName("locale")
+		req := NewRequest(nil)
+		req.WithMethod(http.MethodGet).WithHost("en.domain.com").WithUri("/test")
+		route, ok := newRouteTree(r.Routes()).Match(req)
+		Expect(ok).To(BeTrue())
+		Expect(route.Name()).To(Equal("locale"))
+
+		locale, _ := req.Param("locale")
+		Expect(locale).To(Equal("en"))
+	})
+
+	It("Match should fall back to regular expression when needed", func() {
+		r := NewRouter()
+		r.Get("/files/<path:.+>", nil).WithName("file")
+		r.Get("/files/<id:\\d+>", nil).WithName("id")
+		t := newRouteTree(r.Routes())
+		Expect(len(t.fallback)).To(Equal(1))
+
+		req := NewRequest(nil)
+		req.WithMethod(http.MethodGet).WithUri("/files/a/b.txt")
+		route, ok := t.Match(req)
+		Expect(ok).To(BeTrue())
+		Expect(route.Name()).To(Equal("file"))
+
+		path, _ := req.Param("path")
+		Expect(path).To(Equal("a/b.txt"))
+	})
+
+	It("Router should rebuild tree when routes change", func() {
+		r := &FactoryRouter{routes: make([]Route, 0)}
+		r.Get("/test", nil).WithName("test")
+		req := NewRequest(nil)
+		req.WithMethod(http.MethodGet).WithUri("/test")
+		Expect(r.Route(req)).To(BeNil())
+
+		r.Remove("test")
+		Expect(r.Route(req)).NotTo(BeNil())
+	})
+
+	It("Router should follow routes modified after registration", func() {
+		r := NewRouter()
+		route := r.Get("/test", nil)
+		req := NewRequest(nil)
+		req.WithMethod(http.MethodGet).WithUri("/test")
+		Expect(r.Route(req)).To(BeNil())
+
+		route.WithUri("/other")
+		Expect(r.Route(req)).NotTo(BeNil())
+		req.WithUri("/other")
+		Expect(r.Route(req)).To(BeNil())
+
+		route.WithHost("example.com")
+		Expect(r.Route(req)).NotTo(BeNil())
+		req.WithHost("example.com")
+		Expect(r.Route(req)).To(BeNil())
+
+		route.WithMethod(http.MethodPost)
+		err := r.Route(req)
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_HTTP_METHOD_NOT_ALLOWED))
+	})
+
+	It("Router should match uri from its beginning in tree and fallback alike", func() {
+		r := NewRouter()
+		test := r.Get("/test", nil)
+		r.Get("/users/<id:\\d+>", nil)
+		r.Get("/files/<path:.+>", nil)
+		r.Get("/v1.0/items", nil)
+		Expect(len(newRouteTree(r.Routes()).fallback)).To(Equal(2))
+		req := NewRequest(nil)
+		req.WithMethod(http.MethodGet)
+
+		for _, uri := range []string{"/x/test", "/x/users/1", "/x/files/a", "/x/v1.0/items"} {
+			err := r.Route(req.WithUri(uri))
+			Expect(err).NotTo(BeNil())
+			Expect(err.Code()).To(Equal(ERR_HTTP_NOT_FOUND))
+		}
+		for _, uri := range []string{"/test", "/users/1", "/files/a", "/v1.0/items", "/v1x0/items"} {
+			Expect(r.Route(req.WithUri(uri))).To(BeNil())
+		}
+
+		// regular expression of a single route is not anchored at the beginning
+		_, ok := test.Match(req.WithUri("/x/test"))
+		Expect(ok).To(BeTrue())
+	})
+
+	It("Router should build tree lazily", func() {
+		r := NewRouter().(*FactoryRouter)
+		r.Get("/test", nil)
+		r.Get("/users", nil)
+		Expect(r.tree.Load()).To(BeNil())
+
+		req := NewRequest(nil)
+		req.WithMethod(http.MethodGet).WithUri("/users")
+		Expect(r.Route(req)).To(BeNil())
+		t := r.routeTree()
+		Expect(r.routeTree() == t).To(BeTrue())
+
+		r.Get("/posts", nil)
+		Expect(r.routeTree() == t).To(BeFalse())
+	})
+})
+
+func benchmarkRoutes() (Router, []Request) {
+	r := NewRouter()
+	for i := 0; i < 80; i++ {
+		r.Get(fmt.Sprintf("/v1/resource%d", i), nil)
+		r.Post(fmt.Sprintf("/v1/resource%d", i), nil)
+		r.Get(fmt.Sprintf("/v1/resource%d/<id:\\d+>", i), nil)
+		r.Put(fmt.Sprintf("/v1/resource%d/<id:\\d+>", i), nil)
+		r.Get(fmt.Sprintf("/v1/resource%d/<id:\\d+>/items/<slug:[a-z-]+>", i), nil)
+	}
+
+	requests := make([]Request, 0)
+	for _, uri := range []string{"/v1/resource0", "/v1/resource40/123", "/v1/resource79/123/items/my-item"} {
+		req := NewRequest(nil)
+		req.WithMethod(http.MethodGet).WithUri(uri)
+		requests = append(requests, req)
+	}
+	return r, requests
+}
+
+func BenchmarkRouterTree(b *testing.B) {
+	r, requests := benchmarkRoutes()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, req := range requests {
+			r.Route(req)
+		}
+	}
+}
+
+func BenchmarkRouterLinear(b *testing.B) {
+	r, requests := benchmarkRoutes()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, req := range requests {
+			for _, route := range r.Routes() {
+				if _, ok := route.Match(req); ok == true {
+					break
+				}
+			}
+		}
+	}
+}
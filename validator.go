@@ -0,0 +1,337 @@
+package lapi
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goline/errors"
+)
+
+// Validator validates input, such as request's body or bound parameters
+type Validator interface {
+	// Validate returns ERR_HTTP_BAD_REQUEST listing invalid fields, see FieldErrors
+	Validate(input interface{}) errors.Error
+}
+
+// FieldValidator validates a part of input's fields
+type FieldValidator interface {
+	// ValidateFields validates top-level fields of input which are picked
+	ValidateFields(input interface{}, pick func(field reflect.StructField) bool) errors.Error
+}
+
+// FieldErrors is an error which describes invalid fields, rescuer renders them as a list
+type FieldErrors interface {
+	// Fields returns invalid fields
+	Fields() []FieldError
+}
+
+// FieldError describes an invalid field
+type FieldError struct {
+	// The field's name
+	Field string `json:"field"`
+
+	// Where field comes from, such as query, header. It is empty for body's fields
+	In string `json:"in,omitempty"`
+
+	// The reason
+	Message string `json:"message"`
+}
+
+// causeError aliases errors.Error, so it could be embedded without hiding method Error()
+type causeError = errors.Error
+
+type fieldErrors struct {
+	causeError
+	fields []FieldError
+}
+
+func (e *fieldErrors) Fields() []FieldError {
+	return e.fields
+}
+
+// NewFieldErrors returns ERR_HTTP_BAD_REQUEST which implements FieldErrors
+func NewFieldErrors(fields []FieldError) errors.Error {
+	reasons := make([]string, len(fields))
+	for i, f := range fields {
+		if f.In == "" {
+			reasons[i] = fmt.Sprintf("%s %s", f.Field, f.Message)
+		} else {
+			reasons[i] = fmt.Sprintf("%s %q %s", f.In, f.Field, f.Message)
+		}
+	}
+
+	return &fieldErrors{
+		causeError: errors.New(ERR_HTTP_BAD_REQUEST, fmt.Sprintf("Invalid parameters: %s", strings.Join(reasons, "; "))),
+		fields:     fields,
+	}
+}
+
+// NewValidator returns a Validator reading `validate` tags. Rules are separated by comma:
+//
+//	required        value must not be zero, nil or empty
+//	min=1, max=10   limits number, or length of string, slice and map
+//	enum=a|b|c      value must be one of listed values
+//	regex=^[a-z]+$  string must match pattern, it must be the last rule
+//
+// Unknown rules are skipped, so tags shared with other validators are tolerated.
+// Nested structs, pointers to struct and slices of struct are validated as well
+func NewValidator() Validator {
+	return &FactoryValidator{}
+}
+
+type FactoryValidator struct{}
+
+// validationRegexps caches compiled patterns of regex rules
+var validationRegexps sync.Map
+
+func (v *FactoryValidator) Validate(input interface{}) errors.Error {
+	return v.ValidateFields(input, nil)
+}
+
+func (v *FactoryValidator) ValidateFields(input interface{}, pick func(field reflect.StructField) bool) errors.Error {
+	value := reflect.ValueOf(input)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields, err := v.validateStruct(value, "", pick)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		return NewFieldErrors(fields)
+	}
+	return nil
+}
+
+func (v *FactoryValidator) validateStruct(value reflect.Value, prefix string, pick func(field reflect.StructField) bool) ([]FieldError, errors.Error) {
+	fields := make([]FieldError, 0)
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous == true && sf.Type.Kind() == reflect.Struct {
+			nested, err := v.validateStruct(value.Field(i), prefix, pick)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, nested...)
+			continue
+		}
+
+		if sf.PkgPath != "" || (pick != nil && pick(sf) == false) {
+			continue
+		}
+
+		name, in := v.nameOf(sf)
+		nested, err := v.validateValue(value.Field(i), prefix+name, in, sf.Tag.Get("validate"))
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, nested...)
+	}
+	return fields, nil
+}
+
+// validateValue checks rules of a field, then its nested structs
+func (v *FactoryValidator) validateValue(value reflect.Value, name string, in string, rules string) ([]FieldError, errors.Error) {
+	if rules == "-" {
+		return nil, nil
+	}
+
+	if rules != "" {
+		reason, err := v.check(value, rules)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			return []FieldError{{Field: name, In: in, Message: reason}}, nil
+		}
+	}
+
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+
+	switch {
+	case value.Kind() == reflect.Struct && value.Type() != timeType:
+		return v.validateStruct(value, name+".", nil)
+	case value.Kind() == reflect.Slice || value.Kind() == reflect.Array:
+		fields := make([]FieldError, 0)
+		for i := 0; i < value.Len(); i++ {
+			nested, err := v.validateValue(value.Index(i), fmt.Sprintf("%s[%d]", name, i), in, "")
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, nested...)
+		}
+		return fields, nil
+	}
+	return nil, nil
+}
+
+// check applies rules on value, it returns reason of the first broken rule.
+// It returns error code ERR_VALIDATE_INVALID_RULE if a rule is malformed
+func (v *FactoryValidator) check(value reflect.Value, rules string) (string, errors.Error) {
+	for rules != "" {
+		var rule string
+		if strings.HasPrefix(rules, "regex=") {
+			rule, rules = rules, ""
+		} else if i := strings.Index(rules, ","); i >= 0 {
+			rule, rules = rules[:i], rules[i+1:]
+		} else {
+			rule, rules = rules, ""
+		}
+
+		key, arg := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			key, arg = rule[:i], rule[i+1:]
+		}
+
+		if key == "required" {
+			if v.isEmpty(value) == true {
+				return "is required", nil
+			}
+			continue
+		}
+
+		if v.isEmpty(value) == true && (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) {
+			// optional values are checked only if they are given
+			return "", nil
+		}
+		for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+			value = value.Elem()
+		}
+
+		if reason, err := v.checkRule(value, key, arg); err != nil || reason != "" {
+			return reason, err
+		}
+	}
+	return "", nil
+}
+
+func (v *FactoryValidator) checkRule(value reflect.Value, key string, arg string) (string, errors.Error) {
+	switch key {
+	case "min", "max":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return "", errors.New(ERR_VALIDATE_INVALID_RULE, fmt.Sprintf("Validation rule %s requires a number, got %q", key, arg))
+		}
+		n, isLength := v.measure(value)
+		unit := ""
+		if isLength == true && value.Kind() == reflect.String {
+			unit = " characters long"
+		} else if isLength == true {
+			unit = " items long"
+		}
+		if key == "min" && n < limit {
+			return fmt.Sprintf("must be at least %s%s", arg, unit), nil
+		}
+		if key == "max" && n > limit {
+			return fmt.Sprintf("must be at most %s%s", arg, unit), nil
+		}
+	case "enum":
+		s := fmt.Sprint(value.Interface())
+		for _, item := range strings.Split(arg, "|") {
+			if item == s {
+				return "", nil
+			}
+		}
+		return fmt.Sprintf("must be one of %s", strings.Replace(arg, "|", ", ", -1)), nil
+	case "regex":
+		r, ok := validationRegexps.Load(arg)
+		if ok == false {
+			compiled, err := regexp.Compile(arg)
+			if err != nil {
+				return "", errors.New(ERR_VALIDATE_INVALID_RULE, fmt.Sprintf("Validation rule regex has invalid pattern %s", arg)).
+					WithDebug(err.Error())
+			}
+			r, _ = validationRegexps.LoadOrStore(arg, compiled)
+		}
+		if value.Kind() != reflect.String || r.(*regexp.Regexp).MatchString(value.String()) == false {
+			return fmt.Sprintf("must match %s", arg), nil
+		}
+	default:
+		// rules of other validators, such as goline/validation, are left to them
+		return "", nil
+	}
+	return "", nil
+}
+
+// measure returns number's value, or length of value
+func (v *FactoryValidator) measure(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), false
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), false
+	case reflect.String:
+		return float64(len([]rune(value.String()))), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), true
+	default:
+		return 0, false
+	}
+}
+
+func (v *FactoryValidator) isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	case reflect.Slice, reflect.Map, reflect.String:
+		return value.Len() == 0
+	default:
+		return value.IsZero()
+	}
+}
+
+// nameOf returns name of field which is seen by client
+func (v *FactoryValidator) nameOf(sf reflect.StructField) (name string, in string) {
+	for _, source := range bindingSources {
+		if key, ok := sf.Tag.Lookup(source); ok == true {
+			return key, source
+		}
+	}
+
+	if tag := strings.Split(sf.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+		return tag, ""
+	}
+	return sf.Name, ""
+}
+
+// isParameterField returns true if field is bound from request's parameters
+func isParameterField(sf reflect.StructField) bool {
+	for _, source := range bindingSources {
+		if _, ok := sf.Tag.Lookup(source); ok == true {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePart validates fields of input which are picked, if validator is a FieldValidator.
+// Otherwise all fields are validated
+func validatePart(validator Validator, input interface{}, pick func(field reflect.StructField) bool) errors.Error {
+	if validator == nil {
+		return nil
+	}
+
+	if v, ok := validator.(FieldValidator); ok == true {
+		return v.ValidateFields(input, pick)
+	}
+	return validator.Validate(input)
+}
@@ -0,0 +1,97 @@
+package lapi
+
+import (
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type validatorInput struct {
+	Id      int64             `path:"id" validate:"min=1"`
+	Name    string            `json:"name" validate:"required,max=5"`
+	Role    string            `json:"role" validate:"enum=admin|user"`
+	Code    *string           `json:"code,omitempty" validate:"regex=^[a-z]{2,3}$"`
+	Address *validatorAddress `json:"address" validate:"required"`
+	Phones  []validatorPhone  `json:"phones" validate:"min=1"`
+}
+
+type validatorAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type validatorPhone struct {
+	Number string `json:"number" validate:"regex=^[0-9]+$"`
+}
+
+var _ = Describe("FactoryValidator", func() {
+	It("Validate should return nil for valid input", func() {
+		code := "vn"
+		input := &validatorInput{
+			Id:      1,
+			Name:    "John",
+			Role:    "admin",
+			Code:    &code,
+			Address: &validatorAddress{"Hanoi"},
+			Phones:  []validatorPhone{{"0123"}},
+		}
+		Expect(NewValidator().Validate(input)).To(BeNil())
+	})
+
+	It("Validate should return field errors", func() {
+		code := "VN,1"
+		input := &validatorInput{
+			Name:    "Johnny",
+			Role:    "guest",
+			Code:    &code,
+			Address: &validatorAddress{},
+			Phones:  []validatorPhone{{"0123"}, {"n/a"}},
+		}
+		err := NewValidator().Validate(input)
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code()).To(Equal(ERR_HTTP_BAD_REQUEST))
+		Expect(err.(FieldErrors).Fields()).To(Equal([]FieldError{
+			{"id", "path", "must be at least 1"},
+			{"name", "", "must be at most 5 characters long"},
+			{"role", "", "must be one of admin, user"},
+			{"code", "", "must match ^[a-z]{2,3}$"},
+			{"address.city", "", "is required"},
+			{"phones[1].number", "", "must match ^[0-9]+$"},
+		}))
+		Expect(err.Message()).To(HavePrefix(`Invalid parameters: path "id" must be at least 1; name must be at most 5 characters long`))
+	})
+
+	It("ValidateFields should validate picked fields only", func() {
+		input := &validatorInput{Id: 0, Name: "John", Role: "user", Address: &validatorAddress{"Hanoi"}}
+		err := NewValidator().(FieldValidator).ValidateFields(input, isParameterField)
+		Expect(err.(FieldErrors).Fields()).To(Equal([]FieldError{{"id", "path", "must be at least 1"}}))
+
+		err = NewValidator().(FieldValidator).ValidateFields(input, func(field reflect.StructField) bool {
+			return isParameterField(field) == false
+		})
+		Expect(err.(FieldErrors).Fields()).To(Equal([]FieldError{{"phones", "", "must be at least 1 items long"}}))
+	})
+
+	It("Validate should return error code ERR_VALIDATE_INVALID_RULE for malformed rules", func() {
+		inputs := []interface{}{
+			&struct {
+				Age int `validate:"min=one"`
+			}{},
+			&struct {
+				Code string `validate:"regex=^[a-z"`
+			}{},
+		}
+		for _, input := range inputs {
+			err := NewValidator().Validate(input)
+			Expect(err).NotTo(BeNil())
+			Expect(err.Code()).To(Equal(ERR_VALIDATE_INVALID_RULE))
+		}
+	})
+
+	It("Validate should skip unknown rules", func() {
+		input := &struct {
+			Email string `validate:"required,email,max=5"`
+		}{"a@b.c"}
+		Expect(NewValidator().Validate(input)).To(BeNil())
+	})
+})